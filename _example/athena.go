@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/olympus-go/athena"
+	"github.com/olympus-go/eris/plugins"
+)
+
+// athenaClient plays the games of the Akinator plugin against akinator.com through athena.
+type athenaClient struct {
+	client *athena.Client
+	themes []athena.Theme
+}
+
+// newAthenaClient creates a client along with the themes currently offered.
+func newAthenaClient() (plugins.AkinatorClient, error) {
+	client, err := athena.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	themes, err := athena.GetThemes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &athenaClient{client: client, themes: themes}, nil
+}
+
+func (a *athenaClient) Themes() []string {
+	names := make([]string, 0, len(a.themes))
+	for _, theme := range a.themes {
+		names = append(names, theme.Name)
+	}
+
+	return names
+}
+
+func (a *athenaClient) NewGame(theme int) error {
+	_, err := a.client.NewGame(a.themes[theme])
+	return err
+}
+
+func (a *athenaClient) Question() string {
+	return a.client.Question()
+}
+
+func (a *athenaClient) Answers() []string {
+	return a.client.Answers()
+}
+
+func (a *athenaClient) Answer(answer int) error {
+	_, err := a.client.Answer(answer)
+	return err
+}
+
+func (a *athenaClient) Undo() error {
+	return a.client.Undo()
+}
+
+func (a *athenaClient) Step() int {
+	return a.client.Step()
+}
+
+func (a *athenaClient) Progress() float64 {
+	return a.client.Progress()
+}
+
+func (a *athenaClient) History() []plugins.AkinatorSelection {
+	history := make([]plugins.AkinatorSelection, 0, len(a.client.Selections))
+	for _, selection := range a.client.Selections {
+		history = append(history, plugins.AkinatorSelection{
+			Question: selection.Question,
+			Answer:   fmt.Sprint(selection.Answer),
+		})
+	}
+
+	return history
+}
+
+func (a *athenaClient) Guesses() ([]plugins.AkinatorGuess, error) {
+	response, err := a.client.ListGuesses()
+	if err != nil {
+		return nil, err
+	}
+
+	guesses := make([]plugins.AkinatorGuess, 0, len(response.Parameters.Elements))
+	for _, guess := range response.Parameters.Elements {
+		guesses = append(guesses, plugins.AkinatorGuess{
+			Name:     guess.Element.Name,
+			ImageUrl: guess.Element.AbsolutePicturePath,
+		})
+	}
+
+	return guesses, nil
+}
@@ -6,6 +6,7 @@ replace github.com/olympus-go/eris => ../
 
 require (
 	github.com/bwmarrin/discordgo v0.25.0
+	github.com/olympus-go/athena v0.0.0-20220807010844-952a47b64358
 	github.com/olympus-go/eris v0.0.0-00010101000000-000000000000
 	github.com/rs/zerolog v1.27.0
 )
@@ -21,7 +22,6 @@ require (
 	github.com/mattn/go-isatty v0.0.14 // indirect
 	github.com/miekg/dns v1.1.50 // indirect
 	github.com/olympus-go/apollo v0.0.0-20220807140325-3df7b99dff15 // indirect
-	github.com/olympus-go/athena v0.0.0-20220807010844-952a47b64358 // indirect
 	golang.org/x/crypto v0.0.0-20211215165025-cf75a172585e // indirect
 	golang.org/x/mod v0.4.2 // indirect
 	golang.org/x/net v0.0.0-20220624214902-1bab6f366d9e // indirect
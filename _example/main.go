@@ -1,48 +1,42 @@
 package main
 
 import (
-	"github.com/bwmarrin/discordgo"
-	"github.com/olympus-go/eris"
-	"github.com/olympus-go/eris/plugins"
-	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
+	"context"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/plugins"
 )
 
 func main() {
-	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 
 	token, ok := os.LookupEnv("DISCORD_TOKEN")
 	if !ok {
-		log.Fatal().Msg("env var DISCORD_TOKEN not set")
-	}
-	appId, ok := os.LookupEnv("DISCORD_APP_ID")
-	if !ok {
-		log.Fatal().Msg("env var DISCORD_APP_ID not set")
+		logger.Error("env var DISCORD_TOKEN not set")
+		os.Exit(1)
 	}
 
-	bot, err := eris.NewBot(token, appId)
+	bot, err := eris.NewBot(eris.Config{Token: token}, logger.Handler())
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to create discord session")
+		logger.Error("failed to create discord session", slog.String("error", err.Error()))
+		os.Exit(1)
 	}
 
-	bot.AddPlugin(plugins.Stats())
-
-	bot.DiscordSession.Identify.Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildMessages |
-		discordgo.IntentsGuildVoiceStates | discordgo.IntentMessageContent
-
-	err = bot.Start()
-	if err != nil {
-		log.Fatal().Err(err).Msg("failed to open discord session")
+	if err = bot.AddPlugins(plugins.Rps(logger), plugins.Akinator(logger, newAthenaClient)); err != nil {
+		logger.Error("failed to add plugins", slog.String("error", err.Error()))
+		os.Exit(1)
 	}
 
-	sc := make(chan os.Signal, 1)
-	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt, os.Kill)
-	<-sc
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	if err = bot.Stop(); err != nil {
-		log.Fatal().Err(err).Msg("failed to close discord session")
+	// Run blocks until a signal is received, then waits for in-flight handlers before disconnecting.
+	if err = bot.Run(ctx); err != nil {
+		logger.Error("failed to shut down cleanly", slog.String("error", err.Error()))
+		os.Exit(1)
 	}
 }
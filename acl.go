@@ -0,0 +1,184 @@
+package eris
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Permission is the level of access required to use a route.
+type Permission int
+
+const (
+	// PermissionEveryone lets anyone use a route.
+	PermissionEveryone Permission = iota
+	// PermissionGuildAdmin restricts a route to bot admins, to members holding one of the guild's admin roles, and to
+	// members with the Manage Server permission.
+	PermissionGuildAdmin
+	// PermissionBotAdmin restricts a route to the bot admins listed in Config.AdminIds.
+	PermissionBotAdmin
+)
+
+// Guarded can optionally be implemented by a plugin to restrict who may use its routes. Permissions are keyed by
+// command path or component pattern, as returned by Routes and Components. The permission keyed by "" applies to every
+// route of the plugin that isn't listed explicitly. Routes that aren't covered default to PermissionEveryone.
+//
+// Permissions are checked before any middleware runs. Handlers registered through Handlers aren't covered and have to
+// use IsAdmin themselves.
+type Guarded interface {
+	Permissions() map[string]Permission
+}
+
+// pluginPermission returns the permission the plugin requires for the route.
+func pluginPermission(plugin Plugin, path string) Permission {
+	guarded, ok := plugin.(Guarded)
+	if !ok {
+		return PermissionEveryone
+	}
+
+	permissions := guarded.Permissions()
+	if permission, ok := permissions[path]; ok {
+		return permission
+	}
+
+	return permissions[""]
+}
+
+// IsBotAdmin returns whether the user is listed in Config.AdminIds.
+func (b *Bot) IsBotAdmin(userId string) bool {
+	return slices.Contains(b.config.AdminIds, userId)
+}
+
+// IsAdmin returns whether the user is a bot admin, holds one of the admin roles of the guild, or has the Manage Server
+// permission in it. This is the same check that PermissionGuildAdmin enforces on routes. An empty guildId only checks
+// for bot admins.
+func (b *Bot) IsAdmin(userId string, guildId string) bool {
+	if b.IsBotAdmin(userId) {
+		return true
+	}
+	if guildId == "" {
+		return false
+	}
+
+	member, err := b.shardFor(guildId).State.Member(guildId, userId)
+	if err != nil {
+		session, _ := b.session()
+		if member, err = session.GuildMember(guildId, userId); err != nil {
+			b.Logger.Warn("failed to look up guild member",
+				slog.String("guild_id", guildId),
+				slog.String("user_id", userId),
+				slog.String("error", err.Error()),
+			)
+			return false
+		}
+	}
+
+	// Admin roles don't need the guild's roles, so only compute the permissions if those don't suffice.
+	if b.isGuildAdmin(guildId, member.Roles, 0) {
+		return true
+	}
+
+	permissions, err := b.memberPermissions(guildId, userId, member.Roles)
+	if err != nil {
+		b.Logger.Warn("failed to look up guild permissions",
+			slog.String("guild_id", guildId),
+			slog.String("user_id", userId),
+			slog.String("error", err.Error()),
+		)
+		return false
+	}
+
+	return b.isGuildAdmin(guildId, member.Roles, permissions)
+}
+
+// isGuildAdmin returns whether a member with the roles and guild permissions is an admin of the guild.
+func (b *Bot) isGuildAdmin(guildId string, roleIds []string, permissions int64) bool {
+	if permissions&(discordgo.PermissionManageServer|discordgo.PermissionAdministrator) != 0 {
+		return true
+	}
+
+	return b.hasAdminRole(guildId, roleIds)
+}
+
+// memberPermissions computes the guild level permissions of a member from the guild's roles, as Discord does for the
+// permissions of interaction members. The owner of the guild has every permission.
+func (b *Bot) memberPermissions(guildId string, userId string, roleIds []string) (int64, error) {
+	guild, err := b.shardFor(guildId).State.Guild(guildId)
+	if err != nil {
+		session, _ := b.session()
+		if guild, err = session.Guild(guildId); err != nil {
+			return 0, err
+		}
+	}
+
+	if guild.OwnerID == userId {
+		return discordgo.PermissionAll, nil
+	}
+
+	var permissions int64
+	for _, role := range guild.Roles {
+		// The @everyone role shares its id with the guild.
+		if role.ID == guildId || slices.Contains(roleIds, role.ID) {
+			permissions |= role.Permissions
+		}
+	}
+
+	return permissions, nil
+}
+
+// SetAdminRoles replaces the admin roles of the guild. Members holding any of them are treated as admins of that guild.
+func (b *Bot) SetAdminRoles(guildId string, roleIds ...string) {
+	b.aclLock.Lock()
+	defer b.aclLock.Unlock()
+
+	if len(roleIds) == 0 {
+		delete(b.adminRoles, guildId)
+		return
+	}
+
+	b.adminRoles[guildId] = slices.Clone(roleIds)
+}
+
+// AdminRoles returns the admin roles of the guild.
+func (b *Bot) AdminRoles(guildId string) []string {
+	b.aclLock.RLock()
+	defer b.aclLock.RUnlock()
+
+	return slices.Clone(b.adminRoles[guildId])
+}
+
+// hasAdminRole returns whether any of roleIds is an admin role of the guild.
+func (b *Bot) hasAdminRole(guildId string, roleIds []string) bool {
+	b.aclLock.RLock()
+	defer b.aclLock.RUnlock()
+
+	for _, roleId := range roleIds {
+		if slices.Contains(b.adminRoles[guildId], roleId) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authorize returns whether the user that made the request has the required permission. Guild roles are taken from
+// the interaction itself, so no lookups are needed.
+func (b *Bot) authorize(r *Request, permission Permission) bool {
+	userId := r.UserId()
+
+	switch permission {
+	case PermissionEveryone:
+		return true
+	case PermissionGuildAdmin:
+		if b.IsBotAdmin(userId) {
+			return true
+		}
+		if r.Interaction.Member == nil {
+			return false
+		}
+		return b.isGuildAdmin(r.Interaction.GuildID, r.Interaction.Member.Roles, r.Interaction.Member.Permissions)
+	default:
+		return b.IsBotAdmin(userId)
+	}
+}
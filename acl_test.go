@@ -0,0 +1,73 @@
+package eris_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+func TestIsAdmin(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{AdminIds: []string{"900"}})
+
+	created := make(chan struct{}, 1)
+	bot.AddHandler("guilds", func(_ *discordgo.Session, _ *discordgo.GuildCreate) {
+		created <- struct{}{}
+	})
+
+	member := func(userId string, roleIds ...string) *discordgo.Member {
+		return &discordgo.Member{GuildID: "300", User: &discordgo.User{ID: userId}, Roles: roleIds}
+	}
+	guild := &discordgo.Guild{
+		ID:      "300",
+		OwnerID: "100",
+		Roles: []*discordgo.Role{
+			{ID: "300", Name: "@everyone", Permissions: discordgo.PermissionSendMessages},
+			{ID: "301", Name: "Moderator", Permissions: discordgo.PermissionManageServer},
+			{ID: "302", Name: "Admin", Permissions: discordgo.PermissionAdministrator},
+			{ID: "303", Name: "Helper"},
+		},
+		Members: []*discordgo.Member{
+			member("100"),
+			member("500", "301"),
+			member("501", "302"),
+			member("600", "303"),
+			member("700"),
+		},
+	}
+	if err := server.Dispatch("GUILD_CREATE", guild); err != nil {
+		t.Fatalf("failed to dispatch guild: %v", err)
+	}
+	select {
+	case <-created:
+	case <-time.After(server.Timeout):
+		t.Fatal("guild was never dispatched")
+	}
+
+	for _, test := range []struct {
+		name    string
+		userId  string
+		guildId string
+		want    bool
+	}{
+		{"bot admin", "900", "", true},
+		{"member outside of a guild", "500", "", false},
+		{"owner", "100", "300", true},
+		{"manage server", "500", "300", true},
+		{"administrator", "501", "300", true},
+		{"no admin role yet", "600", "300", false},
+		{"member", "700", "300", false},
+	} {
+		if got := bot.IsAdmin(test.userId, test.guildId); got != test.want {
+			t.Errorf("%s: IsAdmin(%q, %q) = %t, want %t", test.name, test.userId, test.guildId, got, test.want)
+		}
+	}
+
+	bot.SetAdminRoles("300", "303")
+	if !bot.IsAdmin("600", "300") {
+		t.Error("member with an admin role isn't an admin")
+	}
+}
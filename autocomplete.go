@@ -0,0 +1,167 @@
+package eris
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris/utils"
+)
+
+// AutocompleteFunc suggests choices for the option a user is typing in. A returned error is logged, and the user is left
+// without suggestions.
+type AutocompleteFunc func(r *AutocompleteRequest) error
+
+// AutocompleteRouter can optionally be implemented by a plugin to suggest choices for the options of its commands that
+// have Autocomplete set. Providers are keyed by the command path followed by the option name, separated by spaces, e.g.
+// "config plugin" or "21q start questions". Providers run with the permission of the command's route, but without any
+// middleware, since they are called for every keystroke.
+type AutocompleteRouter interface {
+	Autocomplete() map[string]AutocompleteFunc
+}
+
+// AutocompleteRequest is an autocomplete interaction that has been routed to an AutocompleteFunc. The embedded Request
+// holds the options typed so far, so providers can suggest choices based on the other options.
+type AutocompleteRequest struct {
+	*Request
+	// Focused is the option the user is typing in.
+	Focused *discordgo.ApplicationCommandInteractionDataOption
+	// Input is what the user typed into the focused option so far.
+	Input string
+}
+
+// Choices returns a builder for the choices sent back to the user.
+func (r *AutocompleteRequest) Choices() *utils.AutocompleteResponseBuilder {
+	return utils.AutocompleteResponse(r.Session, r.Interaction.Interaction)
+}
+
+type autocompleteRoute struct {
+	plugin     string
+	permission Permission
+	provider   AutocompleteFunc
+}
+
+// addAutocompletes registers the autocomplete providers of a plugin, replacing any it registered before, and fails
+// without changing anything if a key is already taken by another plugin.
+func (b *Bot) addAutocompletes(plugin Plugin) error {
+	router, ok := plugin.(AutocompleteRouter)
+	if !ok {
+		b.removeAutocompletes(plugin.Name())
+		return nil
+	}
+
+	providers := router.Autocomplete()
+
+	b.routesLock.Lock()
+	defer b.routesLock.Unlock()
+
+	for key := range providers {
+		if !strings.Contains(normalizePath(key), " ") {
+			return fmt.Errorf("autocomplete %q isn't a command path followed by an option name", key)
+		}
+		if existing, ok := b.autocompletes[normalizePath(key)]; ok && existing.plugin != plugin.Name() {
+			return fmt.Errorf("autocomplete %q is already registered by plugin %q", key, existing.plugin)
+		}
+	}
+
+	for key, route := range b.autocompletes {
+		if route.plugin == plugin.Name() {
+			delete(b.autocompletes, key)
+		}
+	}
+
+	for key, provider := range providers {
+		key = normalizePath(key)
+		b.autocompletes[key] = autocompleteRoute{
+			plugin:     plugin.Name(),
+			permission: pluginPermission(plugin, key[:strings.LastIndex(key, " ")]),
+			provider:   provider,
+		}
+	}
+
+	return nil
+}
+
+// removeAutocompletes removes every autocomplete provider registered by the plugin.
+func (b *Bot) removeAutocompletes(name string) {
+	b.routesLock.Lock()
+	defer b.routesLock.Unlock()
+
+	for key, route := range b.autocompletes {
+		if route.plugin == name {
+			delete(b.autocompletes, key)
+		}
+	}
+}
+
+// routeAutocomplete dispatches an autocomplete interaction to the provider of its focused option. Commands that don't
+// belong to any loaded plugin get no choices, while those that do are left to the plugin's own handlers if it has no
+// provider for the option.
+func (b *Bot) routeAutocomplete(session *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	path, options := commandPath(data)
+
+	var focused *discordgo.ApplicationCommandInteractionDataOption
+	for _, option := range options {
+		if option.Focused {
+			focused = option
+			break
+		}
+	}
+
+	b.routesLock.RLock()
+	var (
+		route autocompleteRoute
+		ok    bool
+	)
+	if focused != nil {
+		route, ok = b.autocompletes[path+" "+focused.Name]
+	}
+	_, owned := b.commandOwners[data.Name]
+	b.routesLock.RUnlock()
+
+	if !ok {
+		if !owned {
+			utils.AutocompleteResponse(session, i.Interaction).SendWithLog(b.Logger)
+		}
+		return
+	}
+
+	request := &AutocompleteRequest{
+		Request: &Request{
+			Session:     session,
+			Interaction: i,
+			Path:        path,
+			Plugin:      route.plugin,
+			Options:     options,
+			Store:       b.PluginStore(route.plugin),
+			bot:         b,
+		},
+		Focused: focused,
+	}
+	if focused.Value != nil {
+		request.Input = fmt.Sprint(focused.Value)
+	}
+
+	if !b.authorize(request.Request, route.permission) {
+		request.Choices().SendWithLog(b.Logger)
+		return
+	}
+
+	defer func() {
+		if value := recover(); value != nil {
+			b.handlePanic(value, route.plugin, slog.String("path", path+" "+focused.Name), nil, nil)
+		}
+	}()
+
+	if err := route.provider(request); err != nil {
+		b.recordFailure(route.plugin)
+		b.Logger.Error("autocomplete provider failed",
+			slog.String("plugin", route.plugin),
+			slog.String("path", path),
+			slog.String("option", focused.Name),
+			slog.String("error", err.Error()),
+		)
+	}
+}
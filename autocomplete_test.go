@@ -0,0 +1,70 @@
+package eris_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+// autocompletePlugin is a test plugin that also suggests choices for its options.
+type autocompletePlugin struct {
+	*testPlugin
+	providers map[string]eris.AutocompleteFunc
+}
+
+func (p *autocompletePlugin) Autocomplete() map[string]eris.AutocompleteFunc {
+	return p.providers
+}
+
+func TestAutocompleteTooManyChoices(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	command := chatCommand("pick", "Picks an item")
+	command.Options = []*discordgo.ApplicationCommandOption{{
+		Type:         discordgo.ApplicationCommandOptionString,
+		Name:         "item",
+		Description:  "The item",
+		Autocomplete: true,
+	}}
+
+	sent := make(chan error, 1)
+	plugin := &autocompletePlugin{
+		testPlugin: &testPlugin{name: "Picker", commands: map[string]*discordgo.ApplicationCommand{"pick": command}},
+		providers: map[string]eris.AutocompleteFunc{
+			"pick item": func(r *eris.AutocompleteRequest) error {
+				choices := r.Choices()
+				for i := 0; i < 30; i++ {
+					choices.Choice(fmt.Sprintf("item %d", i), i)
+				}
+				err := choices.Send()
+				sent <- err
+				return err
+			},
+		},
+	}
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	interaction := server.InteractionCreate(eristest.Autocomplete("500", "pick",
+		eristest.Focused(eristest.Option("item", discordgo.ApplicationCommandOptionString, ""))))
+
+	// The choices that fit are still sent, but the ones beyond them aren't dropped silently.
+	if response := server.WaitForResponse(interaction.ID); len(response.Data.Choices) != 25 {
+		t.Errorf("sent %d choices, want 25", len(response.Data.Choices))
+	}
+	select {
+	case err := <-sent:
+		if err == nil || !strings.Contains(err.Error(), "5 beyond") {
+			t.Errorf("Send returned %v, want an error reporting 5 dropped choices", err)
+		}
+	case <-time.After(server.Timeout):
+		t.Fatal("provider never sent its choices")
+	}
+}
@@ -1,13 +1,24 @@
 package eris
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/olympus-go/eris/utils"
 )
 
+// ErrShutdown is returned by Start once the bot has been shut down.
+var ErrShutdown = errors.New("bot has been shut down")
+
 type BotState int
 
 const (
@@ -16,186 +27,801 @@ const (
 	UnknownState
 )
 
+func (s BotState) String() string {
+	switch s {
+	case StartedState:
+		return "started"
+	case StoppedState:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
 type Bot struct {
 	discordSession *discordgo.Session
+	shards         []*discordgo.Session
+	shardsLock     sync.RWMutex
 	handlers       map[string]func()
+	handlerFuncs   map[string]any
+	handlerSlots   map[string]*handlerSlot
+	handlersLock   sync.Mutex
+	routes         map[string]route
+	components     []componentRoute
+	autocompletes  map[string]autocompleteRoute
+	middleware     []Middleware
+	failures       map[string]int64
+	failuresLock   sync.Mutex
+	metrics        *metrics
+	subscriptions  map[reflect.Type][]subscription
+	busLock        sync.RWMutex
+	subscriptionId uint64
+	httpServer     *http.Server
+	jobs           map[string]map[string]*scheduledJob
+	tasks          map[string]map[string]TaskFunc
+	jobsLock       sync.Mutex
+	adminRoles     map[string][]string
+	storage        Storage
+	catalog        *Catalog
+	aclLock        sync.RWMutex
+	commandOwners  map[string]string
+	routesLock     sync.RWMutex
 	commands       map[string]func()
+	addedCommands  map[string]map[string]*discordgo.ApplicationCommand
+	commandsLock   sync.Mutex
 	plugins        map[string]Plugin
+	pluginScopes   map[string][]string
+	pluginsLock    sync.RWMutex
+	lifecycleLock  sync.Mutex
+	commandScopes  map[string]struct{}
 	state          BotState
+	config         Config
+	inFlight       sync.WaitGroup
+	closing        bool
+	shutdown       bool
+	closingLock    sync.RWMutex
+	intents        discordgo.Intent
+	baseIntents    discordgo.Intent
 	Logger         *slog.Logger
 }
 
+// NewBot creates a new Bot and connects it to Discord. If config.DeferConnect is set the bot isn't connected until Start
+// or Run is called, which lets it identify with the intents of every plugin added in the meantime.
 func NewBot(config Config, h slog.Handler) (*Bot, error) {
-	var err error
+	session, err := discordgo.New("Bot " + config.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBotWithSession(session, config, h)
+}
 
+// NewBotWithSession is like NewBot but uses the supplied session instead of creating one from config.Token. This can be
+// used to point the bot at a different backend, such as the fake one provided by the eristest package.
+func NewBotWithSession(session *discordgo.Session, config Config, h slog.Handler) (*Bot, error) {
 	if h == nil {
 		h = utils.NopLogHandler{}
 	}
 
 	bot := Bot{
-		handlers: make(map[string]func()),
-		commands: make(map[string]func()),
-		plugins:  make(map[string]Plugin),
-		state:    UnknownState,
-		Logger:   slog.New(h),
+		discordSession: session,
+		shards:         []*discordgo.Session{session},
+		handlers:       make(map[string]func()),
+		handlerFuncs:   make(map[string]any),
+		handlerSlots:   make(map[string]*handlerSlot),
+		routes:         make(map[string]route),
+		autocompletes:  make(map[string]autocompleteRoute),
+		commandOwners:  make(map[string]string),
+		failures:       make(map[string]int64),
+		subscriptions:  make(map[reflect.Type][]subscription),
+		jobs:           make(map[string]map[string]*scheduledJob),
+		tasks:          make(map[string]map[string]TaskFunc),
+		adminRoles:     make(map[string][]string),
+		storage:        NewMemoryStorage(),
+		commands:       make(map[string]func()),
+		addedCommands:  make(map[string]map[string]*discordgo.ApplicationCommand),
+		plugins:        make(map[string]Plugin),
+		pluginScopes:   make(map[string][]string),
+		commandScopes:  map[string]struct{}{"": {}},
+		state:          UnknownState,
+		config:         config,
+		baseIntents:    session.Identify.Intents,
 	}
+	bot.Logger = slog.New(logHandler{Handler: h, bot: &bot})
 
-	bot.discordSession, err = discordgo.New("Bot " + config.Token)
-	if err != nil {
-		return nil, err
+	bot.metrics = newMetrics(&bot)
+
+	defaultLocale := DefaultLocale
+	if config.DefaultLocale != "" {
+		defaultLocale = discordgo.Locale(config.DefaultLocale)
+	}
+	bot.catalog = NewCatalog(defaultLocale)
+	if config.LocalesPath != "" {
+		if err := bot.catalog.Load(os.DirFS(config.LocalesPath)); err != nil {
+			return nil, fmt.Errorf("failed to load locales: %w", err)
+		}
 	}
 
-	if err = bot.Start(); err != nil {
-		return nil, err
+	if config.StoragePath != "" {
+		storage, err := NewBoltStorage(config.StoragePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open storage: %w", err)
+		}
+		bot.storage = storage
 	}
 
-	bot.Logger = bot.Logger.With(slog.Any("eris", bot.botData()))
+	for guildId, roleIds := range config.AdminRoles {
+		bot.SetAdminRoles(guildId, roleIds...)
+	}
+
+	bot.AddHandler("eris_router", bot.routeInteraction)
+	bot.AddHandler("eris_rate_limits", bot.countRateLimit)
+	bot.Use(bot.logRequests)
 
 	bot.AddPlugin(PluginManager{
-		plugins: &bot.plugins,
-		logger:  slog.New(bot.Logger.Handler()),
+		bot: &bot,
 	})
+	bot.AddPlugin(SettingsManager{
+		bot: &bot,
+	})
+
+	if config.MetricsAddr != "" {
+		if err := bot.serveHTTP(config.MetricsAddr); err != nil {
+			_ = bot.storage.Close()
+			return nil, fmt.Errorf("failed to serve metrics: %w", err)
+		}
+	}
+
+	if config.DeferConnect {
+		return &bot, nil
+	}
+
+	if err := bot.Start(); err != nil {
+		_ = bot.storage.Close()
+		if bot.httpServer != nil {
+			_ = bot.httpServer.Close()
+		}
+		return nil, err
+	}
 
 	return &bot, nil
 }
 
+// AddHandler registers a discordgo event handler under the given name, replacing any handler of the same name. Panics
+// in the handler are recovered and logged instead of crashing the bot. The handlers of plugins are kept apart, so they
+// are never replaced by one added here.
 func (b *Bot) AddHandler(name string, handler any) {
-	if _, ok := b.handlers[name]; ok {
-		b.handlers[name]()
+	b.addHandler("", name, handler)
+}
+
+// addHandler registers a handler on behalf of a plugin, so that its failures are attributed to the plugin. Handler
+// names are namespaced per plugin, so only a handler of the same plugin and name is replaced. A handler replacing one
+// of the same type is swapped in place instead of being registered again.
+func (b *Bot) addHandler(plugin string, name string, handler any) {
+	key := handlerKey(plugin, name)
+	handlerValue := reflect.ValueOf(handler)
+	target := &handlerTarget{plugin: plugin, handler: handlerValue}
+
+	b.handlersLock.Lock()
+	defer b.handlersLock.Unlock()
+
+	if slot, ok := b.handlerSlots[key]; ok && handlerValue.Kind() == reflect.Func &&
+		slot.handlerType == handlerValue.Type() {
+		slot.target.Store(target)
+		return
+	}
+
+	b.unregisterHandler(key)
+
+	// Values that aren't functions are registered as is so that discordgo can reject them.
+	wrapped := handler
+	if handlerValue.Kind() == reflect.Func {
+		slot := &handlerSlot{handlerType: handlerValue.Type()}
+		slot.target.Store(target)
+		b.handlerSlots[key] = slot
+		wrapped = b.wrapHandler(name, slot)
 	}
 
-	b.handlers[name] = b.discordSession.AddHandler(handler)
+	b.handlerFuncs[key] = wrapped
+	b.handlers[key] = b.addShardHandler(wrapped)
 }
 
+// RemoveHandler removes a handler added with AddHandler.
 func (b *Bot) RemoveHandler(name string) {
-	if _, ok := b.handlers[name]; ok {
-		b.handlers[name]()
-		delete(b.handlers, name)
+	b.removeHandler("", name)
+}
+
+// removeHandler removes the named handler of a plugin.
+func (b *Bot) removeHandler(plugin string, name string) {
+	b.handlersLock.Lock()
+	defer b.handlersLock.Unlock()
+
+	b.unregisterHandler(handlerKey(plugin, name))
+}
+
+// unregisterHandler removes the handler stored under key from every shard. The handlers lock must be held.
+func (b *Bot) unregisterHandler(key string) {
+	if _, ok := b.handlers[key]; ok {
+		b.handlers[key]()
+		delete(b.handlers, key)
+		delete(b.handlerFuncs, key)
+		delete(b.handlerSlots, key)
+	}
+}
+
+// handlerKey is the key a handler is stored under. Handlers added through AddHandler keep their name, and the handlers
+// of plugins are prefixed with the plugin's name.
+func handlerKey(plugin string, name string) string {
+	if plugin == "" {
+		return name
 	}
+
+	return plugin + "/" + name
 }
 
-// AddCommand registers an ApplicationCommand to the specified guild Ids (global if empty). The supplied command will
-// be compared against currently registered commands to prevent re-registering the same exact command.
+// AddCommand declares an application command that doesn't belong to any plugin in the specified guild Ids (global if
+// empty) and synchronizes those scopes. The command is kept by every later synchronization alongside the commands of
+// plugins, until it is removed with RemoveCommand. Interactions for it aren't routed, so it has to be handled by a
+// handler added with AddHandler. A command that a loaded plugin already declares is rejected.
 func (b *Bot) AddCommand(cmd *discordgo.ApplicationCommand, guildIds ...string) {
 	if len(guildIds) == 0 {
 		guildIds = []string{""}
 	}
 
-	var commandFound bool
-	for _, guildId := range guildIds {
-		commandFound = false
-		registeredCommands, _ := b.discordSession.ApplicationCommands(b.Id(), guildId)
-		for _, registeredCommand := range registeredCommands {
-			if utils.CompareApplicationCommand(*cmd, *registeredCommand) {
-				b.Logger.Debug("skipping command already registered", slog.String("command_name", cmd.Name))
-				commandFound = true
-				break
+	key := commandKey(cmd)
+	for _, loaded := range b.loadedPlugins() {
+		for _, command := range loaded.Commands() {
+			if commandKey(command) == key {
+				b.Logger.Error("failed to add application command",
+					slog.String("command_name", cmd.Name),
+					slog.String("error", fmt.Sprintf("command is already declared by plugin %q", loaded.Name())),
+				)
+				return
 			}
 		}
+	}
 
-		if !commandFound {
-			_, err := b.discordSession.ApplicationCommandCreate(b.Id(), guildId, cmd)
-			if err != nil {
-				b.Logger.Error("failed to create application command",
-					slog.String("error", err.Error()),
-					slog.Any("command", cmd),
-				)
-			}
+	b.commandsLock.Lock()
+	for _, guildId := range guildIds {
+		if b.addedCommands[guildId] == nil {
+			b.addedCommands[guildId] = make(map[string]*discordgo.ApplicationCommand)
 		}
+		b.addedCommands[guildId][key] = cmd
+	}
+	b.commandsLock.Unlock()
+
+	b.routesLock.Lock()
+	if _, ok := b.commandOwners[cmd.Name]; !ok {
+		b.commandOwners[cmd.Name] = ""
 	}
+	b.routesLock.Unlock()
+
+	b.syncPluginCommands(guildIds)
 }
 
+// RemoveCommand removes a command added with AddCommand from the specified guild Ids (global if empty) and synchronizes
+// those scopes. The command is looked up by its id, as registered with Discord. Commands declared by plugins can't be
+// removed this way, since the next synchronization would register them again; remove the plugin instead.
 func (b *Bot) RemoveCommand(cmdId string, guildIds ...string) {
 	if len(guildIds) == 0 {
-		if err := b.discordSession.ApplicationCommandDelete(b.Id(), "", cmdId); err != nil {
-			b.Logger.Error("failed to remove application command", slog.String("error", err.Error()))
+		guildIds = []string{""}
+	}
+
+	var synced []string
+	for _, guildId := range guildIds {
+		key, ok := b.addedCommandKey(cmdId, guildId)
+		if !ok {
+			b.Logger.Error("failed to remove application command",
+				slog.String("command_id", cmdId),
+				slog.String("guild_id", guildId),
+				slog.String("error", "command wasn't added with AddCommand"),
+			)
+			continue
 		}
-	} else {
-		for _, guildId := range guildIds {
-			if err := b.discordSession.ApplicationCommandDelete(b.Id(), guildId, cmdId); err != nil {
-				b.Logger.Error("failed to remove application command", slog.String("error", err.Error()))
+
+		b.commandsLock.Lock()
+		command, ok := b.addedCommands[guildId][key]
+		delete(b.addedCommands[guildId], key)
+		if len(b.addedCommands[guildId]) == 0 {
+			delete(b.addedCommands, guildId)
+		}
+		b.commandsLock.Unlock()
+		// The command may have been removed concurrently while its key was looked up.
+		if !ok {
+			continue
+		}
+		b.releaseAddedCommand(command.Name)
+
+		synced = append(synced, guildId)
+	}
+
+	if len(synced) > 0 {
+		b.syncPluginCommands(synced)
+	}
+}
+
+// addedCommandKey returns the key of the command added with AddCommand to the scope that has the id. Commands that were
+// added before the bot started don't know their id yet, so the registered commands are looked up if needed.
+func (b *Bot) addedCommandKey(cmdId string, guildId string) (string, bool) {
+	b.commandsLock.Lock()
+	for key, command := range b.addedCommands[guildId] {
+		if command.ID == cmdId {
+			b.commandsLock.Unlock()
+			return key, true
+		}
+	}
+	b.commandsLock.Unlock()
+
+	session, state := b.session()
+	if state != StartedState {
+		return "", false
+	}
+
+	registered, err := session.ApplicationCommands(session.State.Application.ID, guildId)
+	if err != nil {
+		return "", false
+	}
+	for _, command := range registered {
+		if command.ID != cmdId {
+			continue
+		}
+		b.commandsLock.Lock()
+		_, ok := b.addedCommands[guildId][commandKey(command)]
+		b.commandsLock.Unlock()
+		if ok {
+			return commandKey(command), true
+		}
+	}
+
+	return "", false
+}
+
+// releaseAddedCommand stops treating the name as a known command once no scope has an added command of that name.
+func (b *Bot) releaseAddedCommand(name string) {
+	b.commandsLock.Lock()
+	defer b.commandsLock.Unlock()
+
+	for _, commands := range b.addedCommands {
+		for _, command := range commands {
+			if command.Name == name {
+				return
 			}
 		}
 	}
+
+	b.routesLock.Lock()
+	if owner, ok := b.commandOwners[name]; ok && owner == "" {
+		delete(b.commandOwners, name)
+	}
+	b.routesLock.Unlock()
 }
 
+// AddIntent adds an intent the bot identifies with. Unlike the intents requested by plugins, it is kept when the plugins
+// requesting it are reloaded.
 func (b *Bot) AddIntent(intent discordgo.Intent) {
-	b.discordSession.Identify.Intents |= intent
+	b.shardsLock.Lock()
+	b.baseIntents |= intent
+	b.shardsLock.Unlock()
+
+	b.addIntent(intent)
 }
 
+// AddPlugin registers a plugin's handlers, routes and intents, and synchronizes its commands to the specified guild Ids
+// (global if empty). If the plugin implements Configurable and Initializer it is configured and initialized first, and
+// nothing is registered if either fails.
 func (b *Bot) AddPlugin(plugin Plugin, guildIds ...string) error {
-	if _, ok := b.plugins[plugin.Name()]; ok {
+	b.lifecycleLock.Lock()
+	defer b.lifecycleLock.Unlock()
+
+	if err := b.addPlugin(plugin, guildIds); err != nil {
+		return err
+	}
+
+	return b.connectPlugins(guildIds, plugin.Name())
+}
+
+// addPlugin registers a plugin without synchronizing its commands, which connectPlugins does once the plugin, or every
+// plugin added along with it, is registered. The lifecycle lock must be held.
+func (b *Bot) addPlugin(plugin Plugin, guildIds []string) error {
+	if _, ok := b.loadedPlugin(plugin.Name()); ok {
 		return fmt.Errorf("plugin already exists")
 	}
 
+	if err := b.checkDependencies(plugin); err != nil {
+		return err
+	}
+	if err := b.checkCommands(plugin); err != nil {
+		return err
+	}
+
+	if err := b.configurePlugin(plugin); err != nil {
+		return fmt.Errorf("failed to configure plugin %q: %w", plugin.Name(), err)
+	}
+
+	if err := b.loadPluginLocales(plugin); err != nil {
+		return err
+	}
+
+	b.setTasks(plugin)
+
+	// rollback undoes what Init and the steps before it left behind. Persisted jobs haven't been restored yet, so the
+	// only jobs forgotten are the ones Init scheduled.
+	rollback := func() {
+		b.catalog.dropDefaults(PluginKey(plugin.Name()) + ".")
+		b.unsubscribePlugin(plugin.Name())
+		b.stopJobs(plugin.Name(), true)
+	}
+
+	if initializer, ok := plugin.(Initializer); ok {
+		if err := initializer.Init(context.Background(), b); err != nil {
+			rollback()
+			return fmt.Errorf("failed to initialize plugin %q: %w", plugin.Name(), err)
+		}
+	}
+
+	// Routes are registered once Init returned, so that the plugin never handles an interaction before it's ready.
+	if err := b.addPluginRoutes(plugin); err != nil {
+		rollback()
+		if closer, ok := plugin.(Closer); ok {
+			if closeErr := closer.Close(context.Background()); closeErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to close plugin %q: %w", plugin.Name(), closeErr))
+			}
+		}
+		return err
+	}
+
+	b.restoreJobs(plugin.Name())
+
+	b.pluginsLock.Lock()
 	b.plugins[plugin.Name()] = plugin
+	b.pluginScopes[plugin.Name()] = guildIds
+	b.pluginsLock.Unlock()
+	b.setCommandOwners(plugin.Name(), nil, plugin.Commands())
 
 	handlers := plugin.Handlers()
 	for name, handler := range handlers {
-		b.AddHandler(name, handler)
+		b.addHandler(plugin.Name(), name, handler)
 	}
 
-	commands := plugin.Commands()
-	for _, command := range commands {
-		b.AddCommand(command, guildIds...)
+	b.addIntent(intentMask(plugin.Intents()))
+
+	return nil
+}
+
+// connectPlugins puts the named plugins, which were just registered, into effect. The current connection can't receive
+// events for intents it didn't identify with, so the bot identifies again if they requested new ones, and Start then
+// synchronizes every plugin's commands. Otherwise only the scopes they were added to are synchronized.
+func (b *Bot) connectPlugins(guildIds []string, names ...string) error {
+	if missing := b.missingIntents(); missing != 0 {
+		b.Logger.Info("re-identifying to pick up new intents",
+			slog.String("plugin", strings.Join(names, ", ")),
+			slog.Int("intents", int(missing)),
+		)
+
+		return b.reidentify()
 	}
 
-	for _, intent := range plugin.Intents() {
-		b.AddIntent(intent)
+	b.syncPluginCommands(guildIds)
+
+	return nil
+}
+
+// addPluginRoutes registers the routes, component routes and autocomplete providers of a plugin, registering none of
+// them if any fails.
+func (b *Bot) addPluginRoutes(plugin Plugin) error {
+	if err := b.addRoutes(plugin); err != nil {
+		return err
+	}
+	if err := b.addComponents(plugin); err != nil {
+		b.removeRoutes(plugin.Name())
+		return err
+	}
+	if err := b.addAutocompletes(plugin); err != nil {
+		b.removeRoutes(plugin.Name())
+		b.removeComponents(plugin.Name())
+		return err
 	}
 
 	return nil
 }
 
+// loadedPlugin returns the loaded plugin with the name.
+func (b *Bot) loadedPlugin(name string) (Plugin, bool) {
+	b.pluginsLock.RLock()
+	defer b.pluginsLock.RUnlock()
+
+	plugin, ok := b.plugins[name]
+
+	return plugin, ok
+}
+
+// loadedPlugins returns a copy of the loaded plugins keyed by name, so that handlers can range over them while plugins
+// are added and removed.
+func (b *Bot) loadedPlugins() map[string]Plugin {
+	b.pluginsLock.RLock()
+	defer b.pluginsLock.RUnlock()
+
+	return maps.Clone(b.plugins)
+}
+
+// pluginScope returns the guild ids the plugin was added to.
+func (b *Bot) pluginScope(name string) []string {
+	b.pluginsLock.RLock()
+	defer b.pluginsLock.RUnlock()
+
+	return b.pluginScopes[name]
+}
+
+// RemovePlugin removes a plugin like UnloadPlugin does and logs any error.
+//
+// Deprecated: Use UnloadPlugin, which reports errors. The guild ids are ignored, since the plugin's commands are deleted
+// from every scope it was added to.
 func (b *Bot) RemovePlugin(plugin Plugin, guildIds ...string) {
-	for name, _ := range plugin.Handlers() {
-		b.RemoveHandler(name)
+	if err := b.UnloadPlugin(plugin.Name()); err != nil {
+		b.Logger.Error("failed to remove plugin",
+			slog.String("plugin", plugin.Name()),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// UnloadPlugin removes a plugin's handlers and deletes its commands from every scope it was added to. If the plugin
+// implements Closer it is closed once it no longer receives events; the plugin is removed even if that fails.
+func (b *Bot) UnloadPlugin(name string) error {
+	b.lifecycleLock.Lock()
+	defer b.lifecycleLock.Unlock()
+
+	plugin, ok := b.loadedPlugin(name)
+	if !ok {
+		return fmt.Errorf("plugin not found")
+	}
+
+	if dependents := b.dependents(name); len(dependents) > 0 {
+		return fmt.Errorf("plugin %q is required by %s", name, strings.Join(dependents, ", "))
 	}
 
-	for _, command := range plugin.Commands() {
-		b.RemoveCommand(command.ID, guildIds...)
+	b.removePlugin(name, plugin.Handlers(), plugin.Commands(), intentMask(plugin.Intents()), true)
+
+	if closer, ok := plugin.(Closer); ok {
+		if err := closer.Close(context.Background()); err != nil {
+			return fmt.Errorf("failed to close plugin %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// removePlugin unregisters everything the named plugin registered with the supplied declarations, and deletes its
+// commands. If forget is set its persisted jobs are deleted as well. The plugin isn't closed.
+func (b *Bot) removePlugin(name string, handlers map[string]any, commands map[string]*discordgo.ApplicationCommand,
+	intents discordgo.Intent, forget bool) {
+	for handlerName := range handlers {
+		b.removeHandler(name, handlerName)
+	}
+
+	b.removeRoutes(name)
+	b.removeComponents(name)
+	b.removeAutocompletes(name)
+	b.unsubscribePlugin(name)
+	b.stopJobs(name, forget)
+	b.catalog.dropDefaults(PluginKey(name) + ".")
+	b.setCommandOwners(name, commands, nil)
+
+	b.pluginsLock.Lock()
+	guildIds := b.pluginScopes[name]
+	delete(b.plugins, name)
+	delete(b.pluginScopes, name)
+	b.pluginsLock.Unlock()
+	b.dropIntents(intents)
+
+	b.syncPluginCommands(guildIds)
+}
+
+// ReloadPlugin closes, configures and initializes a plugin again, then registers it like ReplacePlugin does. Since the
+// plugin is closed before it is initialized again, events that reach it in between find it closed. ReplacePlugin with a
+// fresh instance avoids that. A plugin that fails to be configured, initialized or registered again is unloaded, since
+// it can't be used once closed; its persisted jobs are kept for when it's added again.
+func (b *Bot) ReloadPlugin(name string) error {
+	b.lifecycleLock.Lock()
+	defer b.lifecycleLock.Unlock()
+
+	plugin, ok := b.loadedPlugin(name)
+	if !ok {
+		return fmt.Errorf("plugin not found")
+	}
+
+	return b.replacePlugin(plugin, plugin, true)
+}
+
+// ReplacePlugin swaps the loaded plugin of the same name for a new instance at runtime. The new instance is configured
+// and initialized while the old one keeps handling events. Its handlers, routes, commands and intents then take the
+// place of the old ones, those it no longer declares are removed, and the old instance is closed. The plugin keeps the
+// guild ids it was added with and its scheduled jobs. If the new instance fails to initialize or register, the old one
+// stays loaded.
+func (b *Bot) ReplacePlugin(plugin Plugin) error {
+	b.lifecycleLock.Lock()
+	defer b.lifecycleLock.Unlock()
+
+	old, ok := b.loadedPlugin(plugin.Name())
+	if !ok {
+		return fmt.Errorf("plugin not found")
 	}
 
-	delete(b.plugins, plugin.Name())
+	return b.replacePlugin(old, plugin, false)
 }
 
-func (b *Bot) ReloadPlugin(name string) {
-	if plugin, ok := b.plugins[name]; ok {
-		handlers := plugin.Handlers()
-		for handlerName, handler := range handlers {
-			b.AddHandler(handlerName, handler)
+// replacePlugin registers plugin in place of old. If inPlace is set they're the same instance, which is then closed
+// before it's initialized again.
+func (b *Bot) replacePlugin(old Plugin, plugin Plugin, inPlace bool) error {
+	name := plugin.Name()
+
+	if err := b.checkDependencies(plugin); err != nil {
+		return err
+	}
+	if err := b.checkCommands(plugin); err != nil {
+		return err
+	}
+
+	// An in place reload may change what the plugin declares, so read the old declarations first.
+	handlers := old.Handlers()
+	commands := old.Commands()
+	intents := intentMask(old.Intents())
+
+	if inPlace {
+		if closer, ok := plugin.(Closer); ok {
+			if err := closer.Close(context.Background()); err != nil {
+				return fmt.Errorf("failed to close plugin %q: %w", name, err)
+			}
 		}
 
-		commands := plugin.Commands()
-		for _, command := range commands {
-			b.AddCommand(command)
+		// Init subscribes the plugin again.
+		b.unsubscribePlugin(name)
+	}
+
+	// Subscriptions made after mark belong to the new instance, and jobs holds the jobs of the old one.
+	mark := b.lastSubscriptionId()
+	jobs := b.Jobs(name)
+	started, initialized := false, false
+
+	// discard drops a new instance that failed, leaving the old one registered. A plugin reloaded in place is closed
+	// already, so it is unloaded instead. An instance that was initialized is closed again, as AddPlugin does.
+	discard := func(err error) error {
+		if closer, ok := plugin.(Closer); ok && initialized {
+			if closeErr := closer.Close(context.Background()); closeErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to close plugin %q: %w", name, closeErr))
+			}
 		}
 
-		for _, intent := range plugin.Intents() {
-			b.AddIntent(intent)
+		if inPlace {
+			b.removePlugin(name, handlers, commands, intents, false)
+			return fmt.Errorf("plugin %q was unloaded after failing to reload: %w", name, err)
 		}
+
+		b.unsubscribe(func(s subscription) bool {
+			return s.plugin == name && s.id > mark
+		})
+		if started {
+			// Jobs scheduled by Init replace those of the old instance, so drop them all and schedule the old ones again.
+			b.stopJobs(name, true)
+		}
+		b.setTasks(old)
+		if started {
+			b.rescheduleJobs(name, jobs)
+		}
+		_ = b.loadPluginLocales(old)
+		// The new routes, components and autocomplete providers are already registered if a later step failed.
+		_ = b.addRoutes(old)
+		_ = b.addComponents(old)
+		_ = b.addAutocompletes(old)
+
+		return err
+	}
+
+	if err := b.configurePlugin(plugin); err != nil {
+		return discard(fmt.Errorf("failed to configure plugin %q: %w", name, err))
+	}
+
+	if err := b.loadPluginLocales(plugin); err != nil {
+		return discard(err)
+	}
+
+	// Scheduled jobs are kept, but run the tasks of the new instance.
+	b.setTasks(plugin)
+
+	if initializer, ok := plugin.(Initializer); ok {
+		started = true
+		if err := initializer.Init(context.Background(), b); err != nil {
+			return discard(fmt.Errorf("failed to initialize plugin %q: %w", name, err))
+		}
+		initialized = true
+	}
+
+	// Routes, components and autocomplete providers are swapped at once, so interactions never find them missing.
+	if err := b.addRoutes(plugin); err != nil {
+		return discard(err)
+	}
+	if err := b.addComponents(plugin); err != nil {
+		return discard(err)
+	}
+	if err := b.addAutocompletes(plugin); err != nil {
+		return discard(err)
+	}
+
+	if !inPlace {
+		b.unsubscribe(func(s subscription) bool {
+			return s.plugin == name && s.id <= mark
+		})
 	}
+
+	b.pluginsLock.Lock()
+	b.plugins[name] = plugin
+	b.pluginsLock.Unlock()
+	b.setCommandOwners(name, commands, plugin.Commands())
+
+	current := plugin.Handlers()
+	for handlerName, handler := range current {
+		b.addHandler(name, handlerName, handler)
+	}
+	for handlerName := range handlers {
+		if _, ok := current[handlerName]; !ok {
+			b.removeHandler(name, handlerName)
+		}
+	}
+
+	b.addIntent(intentMask(plugin.Intents()))
+	b.dropIntents(intents)
+
+	var closeErr error
+	if closer, ok := old.(Closer); ok && !inPlace {
+		if err := closer.Close(context.Background()); err != nil {
+			closeErr = fmt.Errorf("failed to close replaced plugin %q: %w", name, err)
+		}
+	}
+
+	return errors.Join(b.connectPlugins(b.pluginScope(name), name), closeErr)
 }
 
 func (b *Bot) Id() string {
-	if b.state == StartedState {
-		return b.discordSession.State.Application.ID
+	if session, state := b.session(); state == StartedState {
+		return session.State.Application.ID
 	}
 
 	return ""
 }
 
 func (b *Bot) Start() error {
-	if b.state == StartedState {
+	if _, state := b.session(); state == StartedState {
 		return nil
 	}
 
-	if err := b.discordSession.Open(); err != nil {
-		b.state = UnknownState
+	b.closingLock.Lock()
+	if b.shutdown {
+		b.closingLock.Unlock()
+		return ErrShutdown
+	}
+	b.closing = false
+	b.closingLock.Unlock()
+
+	b.shardsLock.Lock()
+	b.intents = b.discordSession.Identify.Intents
+	b.shardsLock.Unlock()
+
+	concurrency, err := b.prepareShards()
+	if err != nil {
+		b.setState(UnknownState)
 		return err
 	}
 
-	b.state = StartedState
+	if err = b.openShards(concurrency); err != nil {
+		b.setState(UnknownState)
+		return b.checkDisallowedIntents(err)
+	}
+
+	b.setState(StartedState)
+
+	if _, err := b.SyncCommands(); err != nil {
+		b.Logger.Error("failed to synchronize application commands", slog.String("error", err.Error()))
+	}
 
 	return nil
 }
@@ -209,32 +835,160 @@ func (b *Bot) Restart() error {
 }
 
 func (b *Bot) Stop() error {
-	if b.state == StoppedState {
+	if _, state := b.session(); state == StoppedState {
 		return nil
 	}
 
-	if err := b.discordSession.Close(); err != nil {
-		b.state = UnknownState
+	if err := b.closeShards(); err != nil {
+		b.setState(UnknownState)
 		return err
 	}
 
-	b.state = StoppedState
+	b.setState(StoppedState)
 
 	return nil
 }
 
-func (b *Bot) botData() any {
+// Run starts the bot if it isn't already running and blocks until ctx is cancelled. The bot is then shut down
+// gracefully, giving in-flight handlers up to Config.ShutdownTimeout to finish.
+func (b *Bot) Run(ctx context.Context) error {
+	if err := b.Start(); err != nil {
+		return err
+	}
+
+	<-ctx.Done()
+
+	timeout := b.config.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return b.Shutdown(shutdownCtx)
+}
+
+// Shutdown gracefully stops the bot. New events are no longer handled, handlers that are already running are given
+// until ctx is done to finish, plugins implementing Closer are closed, and finally the gateway connection and the
+// storage are closed.
+// Unlike Stop, the gateway stays open while handlers finish so that they can still send their responses. Shutdown is
+// final: since the plugins and the storage are closed, Start returns ErrShutdown afterwards.
+func (b *Bot) Shutdown(ctx context.Context) error {
+	b.closingLock.Lock()
+	b.closing = true
+	b.shutdown = true
+	b.closingLock.Unlock()
+
+	// Persisted jobs are kept, so that they're scheduled again the next time their plugin is added.
+	for name := range b.loadedPlugins() {
+		b.stopJobs(name, false)
+	}
+
+	var errs []error
+
+	done := make(chan struct{})
+	go func() {
+		b.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		b.Logger.Warn("shutting down with handlers still in flight", slog.String("error", ctx.Err().Error()))
+		errs = append(errs, fmt.Errorf("waiting for handlers: %w", ctx.Err()))
+	}
+
+	// Plugins are closed before the plugins they depend on.
+	plugins := b.loadedPlugins()
+	for _, name := range b.closeOrder() {
+		if closer, ok := plugins[name].(Closer); ok {
+			if err := closer.Close(ctx); err != nil {
+				b.Logger.Error("failed to close plugin", slog.String("plugin", name), slog.String("error", err.Error()))
+				errs = append(errs, fmt.Errorf("closing plugin %q: %w", name, err))
+			}
+		}
+	}
+
+	if err := b.Stop(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := b.storage.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("closing storage: %w", err))
+	}
+
+	if b.httpServer != nil {
+		if err := b.httpServer.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing metrics server: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// cloneSession creates a new session with the same configuration as session. Its handlers are not copied.
+func cloneSession(session *discordgo.Session) *discordgo.Session {
+	clone, _ := discordgo.New(session.Token)
+	clone.Identify = session.Identify
+	clone.Client = session.Client
+	clone.Dialer = session.Dialer
+	clone.UserAgent = session.UserAgent
+	clone.LogLevel = session.LogLevel
+	clone.StateEnabled = session.StateEnabled
+	clone.SyncEvents = session.SyncEvents
+	clone.MaxRestRetries = session.MaxRestRetries
+	clone.ShouldReconnectOnError = session.ShouldReconnectOnError
+	clone.ShouldRetryOnRateLimit = session.ShouldRetryOnRateLimit
+	clone.ShardID = session.ShardID
+	clone.ShardCount = session.ShardCount
+
+	return clone
+}
+
+// botData returns the name and Id of the bot, and whether they're known yet, which they are once the bot is ready.
+func (b *Bot) botData() (any, bool) {
 	type data struct {
 		Name string
 		Id   string
 	}
 
-	var d data
+	session, state := b.session()
+	if state != StartedState {
+		return nil, false
+	}
+
+	session.State.RLock()
+	defer session.State.RUnlock()
 
-	if b.state == StartedState {
-		d.Name = b.discordSession.State.User.Username
-		d.Id = b.discordSession.State.Application.ID
+	if session.State.User == nil || session.State.Application == nil {
+		return nil, false
 	}
 
-	return d
+	return data{Name: session.State.User.Username, Id: session.State.Application.ID}, true
+}
+
+// logHandler adds the name and Id of the bot to every record once it has connected. They're looked up as records are
+// handled, so Bot.Logger is set once when the bot is created and never changes while handlers are using it.
+type logHandler struct {
+	slog.Handler
+	bot *Bot
+}
+
+func (h logHandler) Handle(ctx context.Context, record slog.Record) error {
+	if data, ok := h.bot.botData(); ok {
+		record = record.Clone()
+		record.AddAttrs(slog.Any("eris", data))
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return logHandler{Handler: h.Handler.WithAttrs(attrs), bot: h.bot}
+}
+
+func (h logHandler) WithGroup(name string) slog.Handler {
+	return logHandler{Handler: h.Handler.WithGroup(name), bot: h.bot}
 }
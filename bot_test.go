@@ -0,0 +1,76 @@
+package eris_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+func TestRunShutdown(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	started, release := make(chan struct{}), make(chan struct{})
+	plugin := &lifecyclePlugin{
+		testPlugin: &testPlugin{
+			name:     "Slow",
+			commands: map[string]*discordgo.ApplicationCommand{"slow": chatCommand("slow", "Takes its time")},
+			routes: map[string]eris.HandlerFunc{
+				"slow": func(r *eris.Request) error {
+					close(started)
+					<-release
+					return r.Respond().Message("done").Send()
+				},
+			},
+		},
+	}
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- bot.Run(ctx)
+	}()
+
+	interaction := server.InteractionCreate(eristest.SlashCommand("500", "slow"))
+	select {
+	case <-started:
+	case <-time.After(server.Timeout):
+		t.Fatal("handler wasn't called")
+	}
+
+	// Run has to wait for the handler, which can still respond over the open gateway.
+	cancel()
+	select {
+	case err := <-result:
+		t.Fatalf("Run returned %v while a handler was running", err)
+	default:
+	}
+	close(release)
+
+	if response := server.WaitForResponse(interaction.ID); response.Data.Content != "done" {
+		t.Errorf("response = %q, want %q", response.Data.Content, "done")
+	}
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("Run returned %v", err)
+		}
+	case <-time.After(server.Timeout):
+		t.Fatal("Run didn't return after the handler finished")
+	}
+	if !plugin.closed.Load() {
+		t.Error("plugin wasn't closed on shutdown")
+	}
+
+	if err := bot.Start(); !errors.Is(err, eris.ErrShutdown) {
+		t.Errorf("Start after shutdown returned %v, want ErrShutdown", err)
+	}
+}
@@ -0,0 +1,122 @@
+package eris
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+// subscription is a handler subscribed to events of a single type.
+type subscription struct {
+	id      uint64
+	plugin  string
+	handler func(event any)
+}
+
+// Subscribe registers handler to be called with every event of type E published on the bot, and returns a function
+// that unsubscribes it. Events are matched by their exact type, so subscribers usually use the struct type that the
+// publishing plugin exports, such as plugins.GameFinished.
+//
+// The subscription is owned by plugin and is dropped when that plugin is removed or reloaded, so plugins should
+// subscribe from Init. Panics in handler are recovered and counted against plugin, the same as panics in event handlers.
+func Subscribe[E any](b *Bot, plugin string, handler func(event E)) func() {
+	eventType := reflect.TypeOf((*E)(nil)).Elem()
+
+	b.busLock.Lock()
+	defer b.busLock.Unlock()
+
+	b.subscriptionId++
+	id := b.subscriptionId
+	b.subscriptions[eventType] = append(b.subscriptions[eventType], subscription{
+		id:     id,
+		plugin: plugin,
+		handler: func(event any) {
+			handler(event.(E))
+		},
+	})
+
+	return func() {
+		b.unsubscribe(func(s subscription) bool {
+			return s.id == id
+		})
+	}
+}
+
+// Publish delivers event to every subscriber of its type without waiting for them. Each subscriber is called in its own
+// goroutine, so there's no ordering between subscribers or between consecutive events. Subscribers that are still
+// running when the bot shuts down are waited for like any other handler, and events published after that are dropped.
+func Publish[E any](b *Bot, event E) {
+	for _, s := range b.subscribers(reflect.TypeOf((*E)(nil)).Elem()) {
+		if !b.acquire() {
+			return
+		}
+
+		go func(s subscription) {
+			defer b.inFlight.Done()
+			b.deliver(s, event)
+		}(s)
+	}
+}
+
+// PublishSync delivers event to every subscriber of its type one after another, in the order they subscribed, and
+// returns once all of them are done. A subscriber that panics doesn't keep the others from receiving the event.
+func PublishSync[E any](b *Bot, event E) {
+	for _, s := range b.subscribers(reflect.TypeOf((*E)(nil)).Elem()) {
+		b.deliver(s, event)
+	}
+}
+
+// deliver calls the subscriber with the event, recovering from any panic.
+func (b *Bot) deliver(s subscription, event any) {
+	defer func() {
+		if value := recover(); value != nil {
+			b.handlePanic(value, s.plugin, slog.String("event", fmt.Sprintf("%T", event)), nil, nil)
+		}
+	}()
+
+	s.handler(event)
+}
+
+// subscribers returns the current subscribers of the event type.
+func (b *Bot) subscribers(eventType reflect.Type) []subscription {
+	b.busLock.RLock()
+	defer b.busLock.RUnlock()
+
+	return append([]subscription(nil), b.subscriptions[eventType]...)
+}
+
+// lastSubscriptionId returns the id of the latest subscription. Subscriptions made afterward have greater ids.
+func (b *Bot) lastSubscriptionId() uint64 {
+	b.busLock.RLock()
+	defer b.busLock.RUnlock()
+
+	return b.subscriptionId
+}
+
+// unsubscribePlugin drops every subscription owned by the plugin.
+func (b *Bot) unsubscribePlugin(plugin string) {
+	b.unsubscribe(func(s subscription) bool {
+		return s.plugin == plugin
+	})
+}
+
+// unsubscribe drops the subscriptions matched by drop.
+func (b *Bot) unsubscribe(drop func(s subscription) bool) {
+	b.busLock.Lock()
+	defer b.busLock.Unlock()
+
+	for eventType, subscriptions := range b.subscriptions {
+		kept := subscriptions[:0:0]
+		for _, s := range subscriptions {
+			if !drop(s) {
+				kept = append(kept, s)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(b.subscriptions, eventType)
+		} else {
+			b.subscriptions[eventType] = kept
+		}
+	}
+}
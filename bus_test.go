@@ -0,0 +1,85 @@
+package eris_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+// scored is a test event.
+type scored struct {
+	Player string
+}
+
+func TestEventBus(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	if err := bot.AddPlugin(&testPlugin{name: "Board"}); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	var received []string
+	eris.Subscribe(bot, "Faulty", func(_ scored) {
+		panic("no leaderboard")
+	})
+	eris.Subscribe(bot, "Board", func(e scored) {
+		received = append(received, "board "+e.Player)
+	})
+	unsubscribe := eris.Subscribe(bot, "Stats", func(e scored) {
+		received = append(received, "stats "+e.Player)
+	})
+	eris.Subscribe(bot, "Board", func(_ *scored) {
+		received = append(received, "pointer")
+	})
+
+	// A panicking subscriber doesn't keep the others from receiving the event, and only events of the exact type are
+	// delivered.
+	eris.PublishSync(bot, scored{Player: "500"})
+	if want := []string{"board 500", "stats 500"}; !reflect.DeepEqual(received, want) {
+		t.Errorf("received %v, want %v", received, want)
+	}
+	if failures := bot.Failures()["Faulty"]; failures != 1 {
+		t.Errorf("failures of the panicking subscriber = %d, want 1", failures)
+	}
+
+	// Subscriptions end when unsubscribed, or when the plugin owning them is removed.
+	unsubscribe()
+	if err := bot.UnloadPlugin("Board"); err != nil {
+		t.Fatalf("failed to unload plugin: %v", err)
+	}
+	received = nil
+	eris.PublishSync(bot, scored{Player: "501"})
+	eris.PublishSync(bot, &scored{Player: "501"})
+	if len(received) != 0 {
+		t.Errorf("received %v after unsubscribing", received)
+	}
+}
+
+func TestPublishAsync(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	release := make(chan struct{})
+	delivered := make(chan string, 1)
+	eris.Subscribe(bot, "Board", func(e scored) {
+		<-release
+		delivered <- e.Player
+	})
+
+	// Publish returns without waiting for the subscriber.
+	eris.Publish(bot, scored{Player: "500"})
+	close(release)
+
+	select {
+	case player := <-delivered:
+		if player != "500" {
+			t.Errorf("delivered %q, want 500", player)
+		}
+	case <-time.After(server.Timeout):
+		t.Fatal("event was never delivered")
+	}
+}
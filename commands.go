@@ -0,0 +1,295 @@
+package eris
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris/utils"
+)
+
+// CommandPlan describes the changes needed to bring the commands registered in a single scope in line with the
+// commands declared by the bot's plugins. GuildId is empty for the global scope.
+type CommandPlan struct {
+	GuildId   string
+	Create    []*discordgo.ApplicationCommand
+	Update    []*discordgo.ApplicationCommand
+	Delete    []*discordgo.ApplicationCommand
+	Unchanged []*discordgo.ApplicationCommand
+}
+
+// Changed returns true if applying the plan would modify the registered commands.
+func (p CommandPlan) Changed() bool {
+	return len(p.Create) > 0 || len(p.Update) > 0 || len(p.Delete) > 0
+}
+
+// CommandSyncResult is the outcome of synchronizing a single scope.
+type CommandSyncResult struct {
+	CommandPlan
+	Applied bool
+	Err     error
+}
+
+// CommandSyncReport is returned by SyncCommands and contains one result per synchronized scope.
+type CommandSyncReport struct {
+	Scopes []CommandSyncResult
+}
+
+// Err joins the errors of every scope in the report, returning nil if all scopes synchronized successfully.
+func (r *CommandSyncReport) Err() error {
+	var errs []error
+	for _, scope := range r.Scopes {
+		if scope.Err != nil {
+			errs = append(errs, fmt.Errorf("guild %q: %w", scope.GuildId, scope.Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SyncCommands synchronizes the application commands of every known scope (global and each guild a plugin was added
+// to) with the commands declared by the loaded plugins. If guildIds are supplied only those scopes are synchronized,
+// where an empty string refers to the global scope. Each scope costs one request to fetch the registered commands and,
+// only if something changed, a single bulk overwrite.
+func (b *Bot) SyncCommands(guildIds ...string) (*CommandSyncReport, error) {
+	session, state := b.session()
+	if state != StartedState {
+		return nil, fmt.Errorf("bot is not started")
+	}
+
+	if len(guildIds) == 0 {
+		guildIds = b.knownScopes()
+	}
+
+	sort.Strings(guildIds)
+
+	report := &CommandSyncReport{}
+	for _, guildId := range guildIds {
+		b.commandsLock.Lock()
+		b.commandScopes[guildId] = struct{}{}
+		b.commandsLock.Unlock()
+
+		result := CommandSyncResult{CommandPlan: CommandPlan{GuildId: guildId}}
+
+		plan, err := b.PlanCommands(guildId)
+		if err != nil {
+			result.Err = err
+			report.Scopes = append(report.Scopes, result)
+			continue
+		}
+		result.CommandPlan = plan
+
+		if plan.Changed() {
+			commands := make([]*discordgo.ApplicationCommand, 0, len(plan.Create)+len(plan.Update)+len(plan.Unchanged))
+			commands = append(commands, plan.Create...)
+			commands = append(commands, plan.Update...)
+			commands = append(commands, plan.Unchanged...)
+			if _, err = session.ApplicationCommandBulkOverwrite(session.State.Application.ID, guildId, commands); err != nil {
+				result.Err = err
+			} else {
+				result.Applied = true
+			}
+		}
+
+		b.Logger.Debug("synchronized application commands",
+			slog.String("guild_id", guildId),
+			slog.Int("created", len(plan.Create)),
+			slog.Int("updated", len(plan.Update)),
+			slog.Int("deleted", len(plan.Delete)),
+			slog.Int("unchanged", len(plan.Unchanged)),
+			slog.Bool("applied", result.Applied),
+		)
+
+		report.Scopes = append(report.Scopes, result)
+	}
+
+	return report, report.Err()
+}
+
+// PlanCommands compares the commands currently registered in the supplied scope against the commands declared by the
+// loaded plugins and returns the resulting plan without applying it.
+func (b *Bot) PlanCommands(guildId string) (CommandPlan, error) {
+	plan := CommandPlan{GuildId: guildId}
+
+	session, _ := b.session()
+	registeredCommands, err := session.ApplicationCommands(b.Id(), guildId)
+	if err != nil {
+		return plan, err
+	}
+
+	registered := make(map[string]*discordgo.ApplicationCommand)
+	for _, command := range registeredCommands {
+		registered[commandKey(command)] = command
+	}
+
+	declared := b.scopeCommands(guildId)
+
+	keys := make([]string, 0, len(declared))
+	for key := range declared {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		command := declared[key]
+		registeredCommand, ok := registered[key]
+		switch {
+		case !ok:
+			plan.Create = append(plan.Create, command)
+		case utils.CompareApplicationCommand(*command, *registeredCommand):
+			plan.Unchanged = append(plan.Unchanged, command)
+		default:
+			plan.Update = append(plan.Update, command)
+		}
+	}
+
+	for _, command := range registeredCommands {
+		if _, ok := declared[commandKey(command)]; !ok {
+			plan.Delete = append(plan.Delete, command)
+		}
+	}
+
+	return plan, nil
+}
+
+// scopeCommands gathers the commands of every plugin added to the supplied scope, keyed by commandKey.
+func (b *Bot) scopeCommands(guildId string) map[string]*discordgo.ApplicationCommand {
+	plugins := b.loadedPlugins()
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	commands := make(map[string]*discordgo.ApplicationCommand)
+	for _, name := range names {
+		if !inScope(b.pluginScope(name), guildId) {
+			continue
+		}
+
+		for _, command := range plugins[name].Commands() {
+			commands[commandKey(command)] = b.localizeCommand(name, command)
+		}
+	}
+
+	b.commandsLock.Lock()
+	for key, command := range b.addedCommands[guildId] {
+		if _, ok := commands[key]; !ok {
+			commands[key] = command
+		}
+	}
+	b.commandsLock.Unlock()
+
+	return commands
+}
+
+// knownScopes returns every scope commands are synchronized in: those synchronized before, those plugins were added to
+// and those commands were added to with AddCommand.
+func (b *Bot) knownScopes() []string {
+	scopes := make(map[string]struct{})
+	b.commandsLock.Lock()
+	for guildId := range b.commandScopes {
+		scopes[guildId] = struct{}{}
+	}
+	for guildId := range b.addedCommands {
+		scopes[guildId] = struct{}{}
+	}
+	b.commandsLock.Unlock()
+
+	b.pluginsLock.RLock()
+	for _, guildIds := range b.pluginScopes {
+		for _, guildId := range guildIds {
+			scopes[guildId] = struct{}{}
+		}
+	}
+	b.pluginsLock.RUnlock()
+
+	guildIds := make([]string, 0, len(scopes))
+	for guildId := range scopes {
+		guildIds = append(guildIds, guildId)
+	}
+
+	return guildIds
+}
+
+// checkCommands fails if the plugin declares a command that another loaded plugin already declares, or declares the
+// same command twice. Commands are routed by name regardless of their scope, so they must be unique across plugins.
+func (b *Bot) checkCommands(plugin Plugin) error {
+	owners := make(map[string]string)
+	for name, loaded := range b.loadedPlugins() {
+		if name == plugin.Name() {
+			continue
+		}
+		for _, command := range loaded.Commands() {
+			owners[commandKey(command)] = name
+		}
+	}
+
+	added := make(map[string]struct{})
+	b.commandsLock.Lock()
+	for _, commands := range b.addedCommands {
+		for key := range commands {
+			added[key] = struct{}{}
+		}
+	}
+	b.commandsLock.Unlock()
+
+	declared := make(map[string]struct{})
+	for _, command := range plugin.Commands() {
+		key := commandKey(command)
+		if owner, ok := owners[key]; ok {
+			return fmt.Errorf("command %q is already declared by plugin %q", command.Name, owner)
+		}
+		if _, ok := added[key]; ok {
+			return fmt.Errorf("command %q is already added with AddCommand", command.Name)
+		}
+		if _, ok := declared[key]; ok {
+			return fmt.Errorf("plugin %q declares command %q more than once", plugin.Name(), command.Name)
+		}
+		declared[key] = struct{}{}
+	}
+
+	return nil
+}
+
+// syncPluginCommands synchronizes the scopes a plugin was added to and logs any failures.
+func (b *Bot) syncPluginCommands(guildIds []string) {
+	if _, state := b.session(); state != StartedState {
+		return
+	}
+
+	if len(guildIds) == 0 {
+		guildIds = []string{""}
+	}
+
+	if _, err := b.SyncCommands(guildIds...); err != nil {
+		b.Logger.Error("failed to synchronize application commands", slog.String("error", err.Error()))
+	}
+}
+
+// inScope checks if guildId is one of the plugin's scopes. A plugin without scopes is global.
+func inScope(scopes []string, guildId string) bool {
+	if len(scopes) == 0 {
+		return guildId == ""
+	}
+
+	for _, scope := range scopes {
+		if scope == guildId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// commandKey identifies a command within a scope. Discord allows commands of different types to share a name.
+func commandKey(command *discordgo.ApplicationCommand) string {
+	commandType := command.Type
+	if commandType == 0 {
+		commandType = discordgo.ChatApplicationCommand
+	}
+
+	return fmt.Sprintf("%d:%s", commandType, command.Name)
+}
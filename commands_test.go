@@ -0,0 +1,275 @@
+package eris_test
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+func sortedNames(commands []*discordgo.ApplicationCommand) []string {
+	names := commandNames(commands)
+	sort.Strings(names)
+	return names
+}
+
+func TestPlanCommands(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	global := &testPlugin{name: "Global", commands: map[string]*discordgo.ApplicationCommand{
+		"echo": chatCommand("echo", "Echoes a message"),
+	}}
+	if err := bot.AddPlugin(global); err != nil {
+		t.Fatalf("failed to add global plugin: %v", err)
+	}
+	guild := &testPlugin{name: "Guild", commands: map[string]*discordgo.ApplicationCommand{
+		"poll": chatCommand("poll", "Starts a poll"),
+	}}
+	if err := bot.AddPlugin(guild, "300"); err != nil {
+		t.Fatalf("failed to add guild plugin: %v", err)
+	}
+
+	if got, want := sortedNames(server.Commands("")), []string{"config", "echo", "plugins"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("global commands = %v, want %v", got, want)
+	}
+	if got, want := sortedNames(server.Commands("300")), []string{"poll"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("guild commands = %v, want %v", got, want)
+	}
+
+	// Leave the registered commands out of date: "plugins" is missing, "echo" is outdated and "stale" is left over.
+	var registered []*discordgo.ApplicationCommand
+	for _, command := range server.Commands("") {
+		switch command.Name {
+		case "plugins":
+			continue
+		case "echo":
+			outdated := *command
+			outdated.Description = "Old description"
+			command = &outdated
+		}
+		registered = append(registered, command)
+	}
+	registered = append(registered, chatCommand("stale", "Left over from a previous run"))
+	server.SetCommands("", registered...)
+
+	plan, err := bot.PlanCommands("")
+	if err != nil {
+		t.Fatalf("failed to plan global commands: %v", err)
+	}
+
+	for _, check := range []struct {
+		kind string
+		got  []*discordgo.ApplicationCommand
+		want []string
+	}{
+		{"create", plan.Create, []string{"plugins"}},
+		{"update", plan.Update, []string{"echo"}},
+		{"delete", plan.Delete, []string{"stale"}},
+		{"unchanged", plan.Unchanged, []string{"config"}},
+	} {
+		if got := sortedNames(check.got); !reflect.DeepEqual(got, check.want) {
+			t.Errorf("global %s = %v, want %v", check.kind, got, check.want)
+		}
+	}
+	if !plan.Changed() {
+		t.Error("global plan isn't changed")
+	}
+
+	guildPlan, err := bot.PlanCommands("300")
+	if err != nil {
+		t.Fatalf("failed to plan guild commands: %v", err)
+	}
+	if guildPlan.Changed() {
+		t.Errorf("guild plan is changed: %+v", guildPlan)
+	}
+	if got, want := sortedNames(guildPlan.Unchanged), []string{"poll"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("guild unchanged = %v, want %v", got, want)
+	}
+
+	report, err := bot.SyncCommands()
+	if err != nil {
+		t.Fatalf("failed to synchronize commands: %v", err)
+	}
+	for _, scope := range report.Scopes {
+		if applied := scope.GuildId == ""; scope.Applied != applied {
+			t.Errorf("scope %q applied = %t, want %t", scope.GuildId, scope.Applied, applied)
+		}
+	}
+
+	if got, want := sortedNames(server.Commands("")), []string{"config", "echo", "plugins"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("global commands after sync = %v, want %v", got, want)
+	}
+	if got, want := sortedNames(server.Commands("300")), []string{"poll"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("guild commands after sync = %v, want %v", got, want)
+	}
+}
+
+func TestPlanCommandsPermissions(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	dmPermission, nsfw, permissions := false, true, int64(discordgo.PermissionManageServer)
+	command := chatCommand("purge", "Deletes messages")
+	command.DMPermission = &dmPermission
+	command.NSFW = &nsfw
+	command.DefaultMemberPermissions = &permissions
+	if err := bot.AddPlugin(&testPlugin{name: "Purge", commands: map[string]*discordgo.ApplicationCommand{
+		"purge": command,
+	}}); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	// Registered commands have their type filled in, which doesn't count as a change.
+	plan, err := bot.PlanCommands("")
+	if err != nil {
+		t.Fatalf("failed to plan commands: %v", err)
+	}
+	if plan.Changed() {
+		t.Errorf("plan of synchronized commands is changed: %+v", plan)
+	}
+
+	for _, strip := range []struct {
+		field string
+		apply func(command *discordgo.ApplicationCommand)
+	}{
+		{"DMPermission", func(command *discordgo.ApplicationCommand) { command.DMPermission = nil }},
+		{"NSFW", func(command *discordgo.ApplicationCommand) { command.NSFW = nil }},
+		{"DefaultMemberPermissions", func(command *discordgo.ApplicationCommand) { command.DefaultMemberPermissions = nil }},
+	} {
+		var registered []*discordgo.ApplicationCommand
+		for _, command := range server.Commands("") {
+			if command.Name == "purge" {
+				outdated := *command
+				strip.apply(&outdated)
+				command = &outdated
+			}
+			registered = append(registered, command)
+		}
+		server.SetCommands("", registered...)
+
+		plan, err = bot.PlanCommands("")
+		if err != nil {
+			t.Fatalf("failed to plan commands: %v", err)
+		}
+		if got := sortedNames(plan.Update); !reflect.DeepEqual(got, []string{"purge"}) {
+			t.Errorf("update with %s changed = %v, want [purge]", strip.field, got)
+		}
+
+		if _, err = bot.SyncCommands(""); err != nil {
+			t.Fatalf("failed to synchronize commands: %v", err)
+		}
+	}
+}
+
+func TestSyncCommandsBeforeStart(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{DeferConnect: true})
+
+	guild := &testPlugin{name: "Guild", commands: map[string]*discordgo.ApplicationCommand{
+		"poll": chatCommand("poll", "Starts a poll"),
+	}}
+	if err := bot.AddPlugin(guild, "300"); err != nil {
+		t.Fatalf("failed to add guild plugin: %v", err)
+	}
+
+	if err := bot.Start(); err != nil {
+		t.Fatalf("failed to start bot: %v", err)
+	}
+
+	if got, want := sortedNames(server.Commands("300")), []string{"poll"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("guild commands = %v, want %v", got, want)
+	}
+	if got, want := sortedNames(server.Commands("")), []string{"config", "plugins"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("global commands = %v, want %v", got, want)
+	}
+}
+
+func TestAddCommand(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	bot.AddCommand(chatCommand("ping", "Replies with pong"))
+	bot.AddCommand(chatCommand("ping", "Replies with pong"), "300")
+
+	// A plugin synchronizing the global scope must keep the added command.
+	plugin := &testPlugin{name: "Echo", commands: map[string]*discordgo.ApplicationCommand{
+		"echo": chatCommand("echo", "Echoes a message"),
+	}}
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	if got, want := sortedNames(server.Commands("")), []string{"config", "echo", "ping", "plugins"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("global commands = %v, want %v", got, want)
+	}
+
+	// A plugin can't declare a command that was added.
+	conflicting := &testPlugin{name: "Ping", commands: map[string]*discordgo.ApplicationCommand{
+		"ping": chatCommand("ping", "Another ping"),
+	}}
+	if err := bot.AddPlugin(conflicting); err == nil {
+		t.Error("adding a plugin declaring an added command succeeded")
+	}
+
+	// Nor can a command be added that a plugin declares.
+	bot.AddCommand(chatCommand("echo", "Another echo"), "300")
+	if got, want := sortedNames(server.Commands("300")), []string{"ping"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("guild commands = %v, want %v", got, want)
+	}
+
+	var pingId string
+	for _, command := range server.Commands("") {
+		if command.Name == "ping" {
+			pingId = command.ID
+		}
+	}
+	bot.RemoveCommand(pingId)
+
+	if got, want := sortedNames(server.Commands("")), []string{"config", "echo", "plugins"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("global commands after removal = %v, want %v", got, want)
+	}
+	if got, want := sortedNames(server.Commands("300")), []string{"ping"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("guild commands after global removal = %v, want %v", got, want)
+	}
+
+	// Commands of removed plugins are still deleted, while the added command is kept.
+	if err := bot.UnloadPlugin("Echo"); err != nil {
+		t.Fatalf("failed to remove plugin: %v", err)
+	}
+	if got, want := sortedNames(server.Commands("")), []string{"config", "plugins"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("global commands after removing the plugin = %v, want %v", got, want)
+	}
+}
+
+func TestAddCommandConcurrently(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	// Adding commands in several guilds while every scope is synchronized must not race.
+	var wg sync.WaitGroup
+	for _, guildId := range []string{"300", "301", "302"} {
+		wg.Add(2)
+		go func(guildId string) {
+			defer wg.Done()
+			bot.AddCommand(chatCommand("ping", "Replies with pong"), guildId)
+		}(guildId)
+		go func() {
+			defer wg.Done()
+			if _, err := bot.SyncCommands(); err != nil {
+				t.Errorf("failed to synchronize commands: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, guildId := range []string{"300", "301", "302"} {
+		if got, want := sortedNames(server.Commands(guildId)), []string{"ping"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("commands of guild %s = %v, want %v", guildId, got, want)
+		}
+	}
+}
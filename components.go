@@ -0,0 +1,377 @@
+package eris
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ComponentRouter can optionally be implemented by a plugin to have message component and modal submit interactions
+// routed to it by CustomID. Routes are keyed by patterns where parameters are wrapped in braces, e.g.
+// "rps_move_{move}_{game}". A parameter matches any non-empty text unless it is typed as "{name:int}", in which case it
+// only matches integers. The matched parameters are available through Request.Param and Request.IntParam. Plugins that
+// namespace their CustomIDs with PrefixCustomId start their patterns with CustomIdPrefix.
+type ComponentRouter interface {
+	Components() map[string]HandlerFunc
+}
+
+// maxCustomIdLength is the longest CustomID Discord accepts.
+const maxCustomIdLength = 100
+
+// CustomIdPrefix returns the prefix that namespaces the CustomIDs of the named plugin: its PluginKey followed by a
+// colon, e.g. "rock_paper_scissors:".
+func CustomIdPrefix(plugin string) string {
+	return PluginKey(plugin) + ":"
+}
+
+// PrefixCustomId namespaces a CustomID to the plugin, so that it can't be mistaken for a component of another plugin.
+// It fails if the CustomID is empty, is already prefixed, or would be longer than Discord allows once prefixed.
+func PrefixCustomId(plugin string, customId string) (string, error) {
+	prefix := CustomIdPrefix(plugin)
+
+	switch {
+	case customId == "":
+		return "", fmt.Errorf("empty custom id for plugin %q", plugin)
+	case strings.HasPrefix(customId, prefix):
+		return "", fmt.Errorf("custom id %q is already prefixed for plugin %q", customId, plugin)
+	case len(prefix)+len(customId) > maxCustomIdLength:
+		return "", fmt.Errorf("custom id %q is longer than %d characters once prefixed for plugin %q", customId,
+			maxCustomIdLength, plugin)
+	}
+
+	return prefix + customId, nil
+}
+
+// StripCustomId removes the plugin's prefix from a CustomID created with PrefixCustomId. It fails if the CustomID
+// doesn't belong to the plugin.
+func StripCustomId(plugin string, customId string) (string, error) {
+	stripped, ok := strings.CutPrefix(customId, CustomIdPrefix(plugin))
+	if !ok || stripped == "" {
+		return "", fmt.Errorf("custom id %q doesn't belong to plugin %q", customId, plugin)
+	}
+
+	return stripped, nil
+}
+
+// CustomId namespaces a CustomID to the plugin the request was routed to, like PrefixCustomId.
+func (r *Request) CustomId(customId string) (string, error) {
+	return PrefixCustomId(r.Plugin, customId)
+}
+
+type componentRoute struct {
+	pattern    string
+	plugin     string
+	permission Permission
+	handler    HandlerFunc
+	regexp     *regexp.Regexp
+	// literals is the number of non-parameter characters in the pattern, used to prefer more specific patterns.
+	literals int
+}
+
+var patternParam = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)(?::([a-z]+))?\}`)
+
+// compilePattern converts a CustomID pattern into an anchored regular expression with a named group per parameter.
+func compilePattern(pattern string) (*regexp.Regexp, int, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	literals := 0
+	seen := make(map[string]struct{})
+	last := 0
+	for _, match := range patternParam.FindAllStringSubmatchIndex(pattern, -1) {
+		literal := pattern[last:match[0]]
+		if strings.ContainsAny(literal, "{}") {
+			return nil, 0, fmt.Errorf("pattern %q has a malformed parameter", pattern)
+		}
+		expr.WriteString(regexp.QuoteMeta(literal))
+		literals += len(literal)
+
+		name := pattern[match[2]:match[3]]
+		if _, ok := seen[name]; ok {
+			return nil, 0, fmt.Errorf("pattern %q repeats parameter %q", pattern, name)
+		}
+		seen[name] = struct{}{}
+
+		paramType := ""
+		if match[4] != -1 {
+			paramType = pattern[match[4]:match[5]]
+		}
+
+		switch paramType {
+		case "":
+			expr.WriteString("(?P<" + name + ">.+?)")
+		case "int":
+			expr.WriteString("(?P<" + name + ">-?[0-9]+)")
+		default:
+			return nil, 0, fmt.Errorf("pattern %q has parameter %q of unknown type %q", pattern, name, paramType)
+		}
+
+		last = match[1]
+	}
+
+	literal := pattern[last:]
+	if strings.ContainsAny(literal, "{}") {
+		return nil, 0, fmt.Errorf("pattern %q has a malformed parameter", pattern)
+	}
+	expr.WriteString(regexp.QuoteMeta(literal) + "$")
+	literals += len(literal)
+
+	compiled, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, 0, fmt.Errorf("pattern %q: %w", pattern, err)
+	}
+
+	return compiled, literals, nil
+}
+
+// patternStep is a single step of the automaton a CustomID pattern compiles to: a character of some class, which may
+// repeat, or, for the sign of an integer parameter, be skipped.
+type patternStep struct {
+	// class is a literal character, or one of anyClass and digitClass.
+	class    int
+	repeat   bool
+	optional bool
+}
+
+const (
+	anyClass   = -1
+	digitClass = -2
+)
+
+// patternSteps converts a valid CustomID pattern into its steps.
+func patternSteps(pattern string) []patternStep {
+	var steps []patternStep
+	literal := func(text string) {
+		for index := 0; index < len(text); index++ {
+			steps = append(steps, patternStep{class: int(text[index])})
+		}
+	}
+
+	last := 0
+	for _, match := range patternParam.FindAllStringSubmatchIndex(pattern, -1) {
+		literal(pattern[last:match[0]])
+		if match[4] != -1 && pattern[match[4]:match[5]] == "int" {
+			steps = append(steps, patternStep{class: '-', optional: true}, patternStep{class: digitClass, repeat: true})
+		} else {
+			steps = append(steps, patternStep{class: anyClass, repeat: true})
+		}
+		last = match[1]
+	}
+	literal(pattern[last:])
+
+	return steps
+}
+
+// classesIntersect returns whether some character belongs to both classes.
+func classesIntersect(first, second int) bool {
+	switch {
+	case first == anyClass || second == anyClass:
+		return true
+	case first == digitClass && second == digitClass:
+		return true
+	case first == digitClass:
+		return second >= '0' && second <= '9'
+	case second == digitClass:
+		return first >= '0' && first <= '9'
+	default:
+		return first == second
+	}
+}
+
+// patternsOverlap returns whether some CustomID matches both patterns, in which case only the more specific one is ever
+// routed to. Both patterns are walked at once, one character at a time, looking for a way to reach both their ends.
+func patternsOverlap(first, second string) bool {
+	a, b := patternSteps(first), patternSteps(second)
+
+	// skip moves past the optional steps at the position, as those may match nothing.
+	skip := func(steps []patternStep, position int) []int {
+		positions := []int{position}
+		for position < len(steps) && steps[position].optional {
+			position++
+			positions = append(positions, position)
+		}
+		return positions
+	}
+
+	// A position is the number of steps consumed. A repeating step that matched once may match again from the next
+	// position, so it's tried both ways.
+	type state struct{ i, j int }
+	seen := make(map[state]bool)
+	var queue []state
+	push := func(i, j int) {
+		for _, i := range skip(a, i) {
+			for _, j := range skip(b, j) {
+				if s := (state{i, j}); !seen[s] {
+					seen[s] = true
+					queue = append(queue, s)
+				}
+			}
+		}
+	}
+	push(0, 0)
+
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		if s.i == len(a) && s.j == len(b) {
+			return true
+		}
+
+		// The steps each pattern can take next: the one at its position, or repeating the one before it.
+		next := func(steps []patternStep, position int) [][2]int {
+			var moves [][2]int
+			if position < len(steps) {
+				moves = append(moves, [2]int{steps[position].class, position + 1})
+			}
+			if position > 0 && steps[position-1].repeat {
+				moves = append(moves, [2]int{steps[position-1].class, position})
+			}
+			return moves
+		}
+		for _, moveA := range next(a, s.i) {
+			for _, moveB := range next(b, s.j) {
+				if classesIntersect(moveA[0], moveB[0]) {
+					push(moveA[1], moveB[1])
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// addComponents registers the component routes of a plugin, replacing any it registered before, and fails without
+// changing anything if a pattern is invalid, or if a CustomID it matches is also matched by a pattern of another plugin,
+// since only one of them would ever be routed to.
+func (b *Bot) addComponents(plugin Plugin) error {
+	router, ok := plugin.(ComponentRouter)
+	if !ok {
+		return nil
+	}
+
+	middleware := pluginMiddleware(plugin)
+
+	var added []componentRoute
+	for pattern, handler := range router.Components() {
+		compiled, literals, err := compilePattern(pattern)
+		if err != nil {
+			return err
+		}
+
+		added = append(added, componentRoute{
+			pattern:    pattern,
+			plugin:     plugin.Name(),
+			permission: pluginPermission(plugin, pattern),
+			handler:    Chain(handler, middleware...),
+			regexp:     compiled,
+			literals:   literals,
+		})
+	}
+
+	b.routesLock.Lock()
+	defer b.routesLock.Unlock()
+
+	for _, route := range added {
+		for _, existing := range b.components {
+			if existing.plugin == route.plugin {
+				continue
+			}
+			if existing.pattern == route.pattern {
+				return fmt.Errorf("component pattern %q is already registered by plugin %q", route.pattern,
+					existing.plugin)
+			}
+			if patternsOverlap(existing.pattern, route.pattern) {
+				return fmt.Errorf("component pattern %q overlaps pattern %q of plugin %q", route.pattern,
+					existing.pattern, existing.plugin)
+			}
+		}
+	}
+
+	components := b.components[:0:0]
+	for _, route := range b.components {
+		if route.plugin != plugin.Name() {
+			components = append(components, route)
+		}
+	}
+	b.components = append(components, added...)
+
+	// Try the most specific patterns first so that e.g. "rps_move_{move}_{game}" wins over "rps_{action}".
+	sort.SliceStable(b.components, func(i, j int) bool {
+		if b.components[i].literals != b.components[j].literals {
+			return b.components[i].literals > b.components[j].literals
+		}
+		return b.components[i].pattern < b.components[j].pattern
+	})
+
+	return nil
+}
+
+// removeComponents removes every component route registered by the plugin.
+func (b *Bot) removeComponents(name string) {
+	b.routesLock.Lock()
+	defer b.routesLock.Unlock()
+
+	components := b.components[:0]
+	for _, route := range b.components {
+		if route.plugin != name {
+			components = append(components, route)
+		}
+	}
+	b.components = components
+}
+
+// matchComponent returns the first component route matching the CustomID along with its parameters. The route has no
+// handler if none matches.
+func (b *Bot) matchComponent(customId string) (componentRoute, map[string]string) {
+	b.routesLock.RLock()
+	defer b.routesLock.RUnlock()
+
+	for _, route := range b.components {
+		submatches := route.regexp.FindStringSubmatch(customId)
+		if submatches == nil {
+			continue
+		}
+
+		params := make(map[string]string, len(submatches)-1)
+		for index, name := range route.regexp.SubexpNames() {
+			if name != "" {
+				params[name] = submatches[index]
+			}
+		}
+		return route, params
+	}
+
+	return componentRoute{}, nil
+}
+
+// routeComponent dispatches a message component or modal submit to the first component route matching its CustomID.
+// Interactions that don't match any route are left to the plugins' own handlers.
+func (b *Bot) routeComponent(session *discordgo.Session, i *discordgo.InteractionCreate) {
+	var customId string
+	switch i.Type {
+	case discordgo.InteractionMessageComponent:
+		customId = i.MessageComponentData().CustomID
+	case discordgo.InteractionModalSubmit:
+		customId = i.ModalSubmitData().CustomID
+	default:
+		return
+	}
+
+	matched, params := b.matchComponent(customId)
+	if matched.handler == nil {
+		b.Logger.Debug("received unrouted component", slog.String("custom_id", customId))
+		return
+	}
+
+	b.dispatch(matched.plugin, matched.permission, matched.handler, &Request{
+		Session:     session,
+		Interaction: i,
+		Path:        matched.pattern,
+		Plugin:      matched.plugin,
+		Params:      params,
+	})
+}
@@ -0,0 +1,109 @@
+package eris_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+func TestRouteComponents(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	plugin := &testPlugin{name: "Shop", components: map[string]eris.HandlerFunc{
+		"item_{id:int}": func(r *eris.Request) error {
+			return r.Respond().Message(fmt.Sprintf("id %d", r.IntParam("id"))).Send()
+		},
+		"item_{name}": func(r *eris.Request) error {
+			return r.Respond().Message("name " + r.Param("name")).Send()
+		},
+		"order_{item}_{amount:int}": func(r *eris.Request) error {
+			return r.Respond().Message(fmt.Sprintf("order %s %d %s", r.Param("item"), r.IntParam("amount"),
+				r.ModalValue("note"))).Send()
+		},
+	}}
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	for _, test := range []struct {
+		interaction *discordgo.Interaction
+		want        string
+	}{
+		{eristest.Component("500", "item_-12"), "id -12"},
+		// A CustomID that isn't an integer falls through to the untyped parameter.
+		{eristest.Component("500", "item_new"), "name new"},
+		{eristest.ModalSubmit("500", "order_apple_3", map[string]string{"note": "ripe"}), "order apple 3 ripe"},
+	} {
+		interaction := server.InteractionCreate(test.interaction)
+		if response := server.WaitForResponse(interaction.ID); response.Data.Content != test.want {
+			t.Errorf("response = %q, want %q", response.Data.Content, test.want)
+		}
+	}
+}
+
+func TestAddComponentsOverlap(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	handler := func(r *eris.Request) error {
+		return r.Respond().Message("ok").Send()
+	}
+
+	for _, test := range []struct {
+		first, second string
+		overlap       bool
+	}{
+		{"x_{a}", "x_{a}", true},
+		{"x_{a}", "x_{a}_{b}", true},
+		{"rps_{m}", "rps_move_{g}", true},
+		{"item_{id:int}", "item_-5", true},
+		{"item_{id:int}", "item_{name}", true},
+		{"item_{id:int}", "item_new", false},
+		{"item_{id:int}_a", "item_{id:int}_b", false},
+		{"21q_answer_{answer:int}_{owner}", "21q_guess_{guess}_{owner}", false},
+		{"rock_paper_scissors:{id}", "akinator:{id}", false},
+	} {
+		first := &testPlugin{name: "First", components: map[string]eris.HandlerFunc{test.first: handler}}
+		if err := bot.AddPlugin(first); err != nil {
+			t.Fatalf("failed to add plugin with pattern %q: %v", test.first, err)
+		}
+
+		second := &testPlugin{name: "Second", components: map[string]eris.HandlerFunc{test.second: handler}}
+		err := bot.AddPlugin(second)
+		if (err != nil) != test.overlap {
+			t.Errorf("adding %q after %q returned %v, want overlap %t", test.second, test.first, err, test.overlap)
+		}
+
+		_ = bot.UnloadPlugin("First")
+		if err == nil {
+			_ = bot.UnloadPlugin("Second")
+		}
+	}
+}
+
+func TestAddComponentsOverlapWithinPlugin(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	// A plugin may overlap its own patterns, in which case the more specific one is routed to.
+	plugin := &testPlugin{name: "Rps", components: map[string]eris.HandlerFunc{
+		"rps_{action}": func(r *eris.Request) error {
+			return r.Respond().Message("action").Send()
+		},
+		"rps_move_{game}": func(r *eris.Request) error {
+			return r.Respond().Message("move " + r.Param("game")).Send()
+		},
+	}}
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	interaction := server.InteractionCreate(eristest.Component("500", "rps_move_7"))
+	if response := server.WaitForResponse(interaction.ID); response.Data.Content != "move 7" {
+		t.Errorf("response = %q, want move 7", response.Data.Content)
+	}
+}
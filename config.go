@@ -1,6 +1,300 @@
 package eris
 
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultShutdownTimeout is how long Run waits for in-flight handlers to finish when Config.ShutdownTimeout is unset.
+const DefaultShutdownTimeout = 10 * time.Second
+
+// EnvPrefix is the prefix of the environment variables that override values loaded by LoadConfig. Variables are named
+// after the yaml key of the field, e.g. ERIS_TOKEN or ERIS_SHUTDOWN_TIMEOUT, and plugin fields are prefixed by their
+// section, e.g. ERIS_PLUGINS_AKINATOR_QUESTIONS. Lists are comma separated.
+const EnvPrefix = "ERIS_"
+
 type Config struct {
-	Token    string
-	AdminIds []string `yaml:"admin_ids"`
+	Token           string        `yaml:"token"`
+	TokenFile       string        `yaml:"token_file"`
+	AdminIds        []string      `yaml:"admin_ids"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// StoragePath is the bbolt database file plugin data is persisted in. Data is only kept in memory if it's unset.
+	StoragePath string `yaml:"storage_path"`
+	// AdminRoles maps guild ids to the roles whose members are admins of that guild.
+	AdminRoles map[string][]string `yaml:"admin_roles"`
+	// DeferConnect delays connecting to Discord until Start or Run is called, so that the intents of every plugin
+	// added beforehand are included when identifying.
+	DeferConnect bool `yaml:"defer_connect"`
+	// MetricsAddr is the address, such as ":9090", that Prometheus metrics and the health check are served on. Nothing
+	// is served if it's unset. See Bot.HTTPHandler.
+	MetricsAddr string `yaml:"metrics_addr"`
+	// ShardCount is the number of gateway shards to run. Zero uses the count recommended by Discord.
+	ShardCount int `yaml:"shard_count"`
+	// LocalesPath is a directory of message catalogs, one file per locale such as "fr.yaml". See Catalog.Load.
+	LocalesPath string `yaml:"locales_path"`
+	// DefaultLocale is the locale messages fall back to when none of the requested locales has them. It defaults to
+	// en-US.
+	DefaultLocale string `yaml:"default_locale"`
+	// Plugins holds the configuration sections of plugins, keyed by PluginKey. A section is decoded into the plugin's
+	// config struct by its yaml field names, so it can be a map[string]any, as LoadConfig produces, or a value of the
+	// config struct itself. See Configurable.
+	Plugins map[string]any `yaml:"plugins"`
+}
+
+// Configurable can optionally be implemented by a plugin that takes configuration. Config returns a pointer to the
+// plugin's config struct, already holding its defaults. AddPlugin decodes the plugin's section of Config.Plugins into
+// it, applies environment overrides, and calls Validate if the struct has such a method, all before Init is called.
+type Configurable interface {
+	Config() any
+}
+
+// FieldError is a problem with a single configuration field, identified by its yaml path.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// LoadConfig reads a Config from a YAML or JSON file and applies environment overrides on top of it. If no token is
+// set but a token file is, the token is read from that file. The returned error joins a FieldError for every field that
+// failed to parse or validate.
+func LoadConfig(path string) (Config, error) {
+	var config Config
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+
+	// JSON is valid YAML, so both are decoded the same way and share the yaml field names.
+	if err = yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	var errs []error
+	errs = append(errs, applyEnv(EnvPrefix, reflect.ValueOf(&config).Elem(), "")...)
+
+	if config.Token == "" && config.TokenFile != "" {
+		token, err := os.ReadFile(config.TokenFile)
+		if err != nil {
+			errs = append(errs, &FieldError{Field: "token_file", Message: err.Error()})
+		}
+		config.Token = strings.TrimSpace(string(token))
+	}
+
+	if err = config.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return config, errors.Join(errs...)
+}
+
+// Validate checks that the required fields are set and that every field holds a sensible value.
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Token == "" {
+		errs = append(errs, &FieldError{Field: "token", Message: "is required, set token, token_file or " +
+			EnvPrefix + "TOKEN"})
+	}
+
+	for index, adminId := range c.AdminIds {
+		if !isSnowflake(adminId) {
+			errs = append(errs, &FieldError{Field: fmt.Sprintf("admin_ids[%d]", index),
+				Message: fmt.Sprintf("%q is not a user id", adminId)})
+		}
+	}
+
+	for guildId, roleIds := range c.AdminRoles {
+		if !isSnowflake(guildId) {
+			errs = append(errs, &FieldError{Field: "admin_roles", Message: fmt.Sprintf("%q is not a guild id", guildId)})
+		}
+		for index, roleId := range roleIds {
+			if !isSnowflake(roleId) {
+				errs = append(errs, &FieldError{Field: fmt.Sprintf("admin_roles.%s[%d]", guildId, index),
+					Message: fmt.Sprintf("%q is not a role id", roleId)})
+			}
+		}
+	}
+
+	if c.ShardCount < 0 {
+		errs = append(errs, &FieldError{Field: "shard_count", Message: "must not be negative"})
+	}
+
+	if c.DefaultLocale != "" && !validLocale(c.DefaultLocale) {
+		errs = append(errs, &FieldError{Field: "default_locale",
+			Message: fmt.Sprintf("%q is not a Discord locale", c.DefaultLocale)})
+	}
+
+	if c.ShutdownTimeout < 0 {
+		errs = append(errs, &FieldError{Field: "shutdown_timeout", Message: "must not be negative"})
+	}
+
+	return errors.Join(errs...)
+}
+
+// PluginKey returns the key of a plugin's section in Config.Plugins: its name in lowercase with spaces and dashes
+// replaced by underscores, e.g. "rock_paper_scissors".
+func PluginKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return '_'
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(name)))
+}
+
+// configurePlugin decodes the plugin's config section into its config struct and validates it.
+func (b *Bot) configurePlugin(plugin Plugin) error {
+	configurable, ok := plugin.(Configurable)
+	if !ok {
+		return nil
+	}
+
+	target := configurable.Config()
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("plugin %q returned a non-pointer config of type %T", plugin.Name(), target)
+	}
+
+	key := PluginKey(plugin.Name())
+	field := "plugins." + key
+
+	if section, ok := b.config.Plugins[key]; ok {
+		if err := decodeSection(section, target); err != nil {
+			return &FieldError{Field: field, Message: err.Error()}
+		}
+	}
+
+	errs := applyEnv(EnvPrefix+"PLUGINS_"+strings.ToUpper(key)+"_", value.Elem(), field+".")
+
+	if validator, ok := target.(interface{ Validate() error }); ok {
+		if err := validator.Validate(); err != nil {
+			errs = append(errs, &FieldError{Field: field, Message: err.Error()})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// decodeSection decodes a plugin's config section into its config struct. The section is encoded as yaml first, so
+// that any value with the same field names decodes the same way as the section of a loaded config file.
+func decodeSection(section any, target any) error {
+	data, err := yaml.Marshal(section)
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(data, target)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv overrides the yaml tagged scalar fields of a struct with the environment variables named after them.
+// Nested structs are descended into, with their key added to the prefix.
+func applyEnv(prefix string, value reflect.Value, field string) []error {
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs []error
+	for index := 0; index < value.NumField(); index++ {
+		structField := value.Type().Field(index)
+		key, _, _ := strings.Cut(structField.Tag.Get("yaml"), ",")
+		if key == "" || key == "-" || !structField.IsExported() {
+			continue
+		}
+
+		fieldValue := value.Field(index)
+		if fieldValue.Kind() == reflect.Struct && fieldValue.Type() != reflect.TypeOf(yaml.Node{}) {
+			errs = append(errs, applyEnv(prefix+strings.ToUpper(key)+"_", fieldValue, field+key+".")...)
+			continue
+		}
+
+		env, ok := os.LookupEnv(prefix + strings.ToUpper(key))
+		if !ok {
+			continue
+		}
+
+		if err := setFromString(fieldValue, env); err != nil {
+			errs = append(errs, &FieldError{Field: field + key,
+				Message: fmt.Sprintf("invalid value in %s: %s", prefix+strings.ToUpper(key), err)})
+		}
+	}
+
+	return errs
+}
+
+// setFromString parses s into a scalar or a comma separated list of scalars. Values of other kinds are rejected.
+func setFromString(value reflect.Value, s string) error {
+	if value.Type() == durationType {
+		duration, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(s)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(s, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(s, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(s, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(parsed)
+	case reflect.Slice:
+		var parts []string
+		if s != "" {
+			parts = strings.Split(s, ",")
+		}
+
+		slice := reflect.MakeSlice(value.Type(), len(parts), len(parts))
+		for index, part := range parts {
+			if err := setFromString(slice.Index(index), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+	default:
+		return fmt.Errorf("fields of type %s can't be set from the environment", value.Type())
+	}
+
+	return nil
+}
+
+// isSnowflake returns whether s looks like a Discord id.
+func isSnowflake(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
 }
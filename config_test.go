@@ -0,0 +1,94 @@
+package eris_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+type gameConfig struct {
+	Rounds int    `yaml:"rounds"`
+	Mode   string `yaml:"mode"`
+}
+
+// configurablePlugin is a test plugin that takes a gameConfig.
+type configurablePlugin struct {
+	*testPlugin
+	config gameConfig
+}
+
+func (p *configurablePlugin) Config() any {
+	return &p.config
+}
+
+func newConfigurablePlugin() *configurablePlugin {
+	return &configurablePlugin{testPlugin: &testPlugin{name: "Game"}, config: gameConfig{Rounds: 3, Mode: "classic"}}
+}
+
+func TestConfigurePlugin(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		section any
+		want    gameConfig
+	}{
+		{"no section", nil, gameConfig{Rounds: 3, Mode: "classic"}},
+		{"map", map[string]any{"rounds": 5}, gameConfig{Rounds: 5, Mode: "classic"}},
+		{"struct", gameConfig{Rounds: 7, Mode: "blitz"}, gameConfig{Rounds: 7, Mode: "blitz"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := eris.Config{DeferConnect: true}
+			if test.section != nil {
+				config.Plugins = map[string]any{"game": test.section}
+			}
+			bot := newTestBot(t, eristest.NewServer(t), config)
+
+			plugin := newConfigurablePlugin()
+			if err := bot.AddPlugin(plugin); err != nil {
+				t.Fatalf("failed to add plugin: %v", err)
+			}
+			if plugin.config != test.want {
+				t.Errorf("config = %+v, want %+v", plugin.config, test.want)
+			}
+		})
+	}
+}
+
+func TestConfigurePluginFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "token: secret\nplugins:\n  game:\n    rounds: 9\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	t.Setenv("ERIS_PLUGINS_GAME_MODE", "blitz")
+
+	config, err := eris.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	config.DeferConnect = true
+	bot := newTestBot(t, eristest.NewServer(t), config)
+
+	plugin := newConfigurablePlugin()
+	if err = bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+	if want := (gameConfig{Rounds: 9, Mode: "blitz"}); plugin.config != want {
+		t.Errorf("config = %+v, want %+v", plugin.config, want)
+	}
+}
+
+func TestConfigurePluginInvalidSection(t *testing.T) {
+	config := eris.Config{DeferConnect: true, Plugins: map[string]any{"game": map[string]any{"rounds": "many"}}}
+	bot := newTestBot(t, eristest.NewServer(t), config)
+
+	err := bot.AddPlugin(newConfigurablePlugin())
+
+	var fieldErr *eris.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "plugins.game" {
+		t.Errorf("error = %v, want a FieldError for plugins.game", err)
+	}
+}
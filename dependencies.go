@@ -0,0 +1,233 @@
+package eris
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Dependent can optionally be implemented by a plugin that relies on other plugins, such as a leaderboard that ranks the
+// players of a game. Dependencies returns the names of those plugins. A plugin is only added once its dependencies are
+// loaded, and a plugin can't be removed while others that depend on it are loaded.
+type Dependent interface {
+	Dependencies() []string
+}
+
+// MissingDependencyError is returned when a plugin depends on a plugin that is neither loaded nor being added.
+type MissingDependencyError struct {
+	Plugin     string
+	Dependency string
+}
+
+func (e *MissingDependencyError) Error() string {
+	return fmt.Sprintf("plugin %q depends on plugin %q, which isn't loaded", e.Plugin, e.Dependency)
+}
+
+// DependencyCycleError is returned when plugins depend on each other. Cycle lists the plugins involved in order,
+// starting and ending with the same one.
+type DependencyCycleError struct {
+	Cycle []string
+}
+
+func (e *DependencyCycleError) Error() string {
+	return fmt.Sprintf("plugins depend on each other: %s", strings.Join(e.Cycle, " -> "))
+}
+
+// AddPlugins adds several plugins globally, ordering them so that every plugin is added after the plugins it depends on.
+// Plugins that don't depend on each other are added in the order they're supplied. Nothing is added if a dependency is
+// missing or the plugins depend on each other in a cycle. If adding a plugin fails, the plugins added before it stay
+// loaded. Commands are synchronized once every plugin is registered, rather than once per plugin.
+func (b *Bot) AddPlugins(plugins ...Plugin) error {
+	b.lifecycleLock.Lock()
+	defer b.lifecycleLock.Unlock()
+
+	order, err := b.loadOrder(plugins)
+	if err != nil {
+		return err
+	}
+
+	added := make([]string, 0, len(order))
+	for _, plugin := range order {
+		if err = b.addPlugin(plugin, nil); err != nil {
+			err = fmt.Errorf("adding plugin %q: %w", plugin.Name(), err)
+			break
+		}
+		added = append(added, plugin.Name())
+	}
+
+	if len(added) > 0 {
+		err = errors.Join(err, b.connectPlugins(nil, added...))
+	}
+
+	return err
+}
+
+// pluginDependencies returns the names of the plugins that plugin depends on.
+func pluginDependencies(plugin Plugin) []string {
+	if dependent, ok := plugin.(Dependent); ok {
+		return dependent.Dependencies()
+	}
+
+	return nil
+}
+
+// checkDependencies checks that every dependency of the plugin is loaded. The plugin itself may already be loaded, in
+// which case its dependencies must not depend on it in turn.
+func (b *Bot) checkDependencies(plugin Plugin) error {
+	loaded := b.loadedPlugins()
+	for _, dependency := range pluginDependencies(plugin) {
+		if _, ok := loaded[dependency]; !ok {
+			return &MissingDependencyError{Plugin: plugin.Name(), Dependency: dependency}
+		}
+	}
+
+	if _, ok := loaded[plugin.Name()]; !ok {
+		return nil
+	}
+
+	plugins := make([]Plugin, 0, len(loaded))
+	for name, loaded := range loaded {
+		if name != plugin.Name() {
+			plugins = append(plugins, loaded)
+		}
+	}
+
+	_, err := sortPlugins(append(plugins, plugin))
+
+	return err
+}
+
+// loadOrder returns the plugins ordered so that each comes after the plugins it depends on. Dependencies may also be
+// plugins that are already loaded.
+func (b *Bot) loadOrder(plugins []Plugin) ([]Plugin, error) {
+	loaded := b.loadedPlugins()
+	batch := make(map[string]struct{}, len(plugins))
+	for _, plugin := range plugins {
+		if _, ok := loaded[plugin.Name()]; ok {
+			return nil, fmt.Errorf("plugin %q already exists", plugin.Name())
+		}
+		if _, ok := batch[plugin.Name()]; ok {
+			return nil, fmt.Errorf("plugin %q is supplied more than once", plugin.Name())
+		}
+		batch[plugin.Name()] = struct{}{}
+	}
+
+	for _, plugin := range plugins {
+		for _, dependency := range pluginDependencies(plugin) {
+			_, isLoaded := loaded[dependency]
+			if _, ok := batch[dependency]; !ok && !isLoaded {
+				return nil, &MissingDependencyError{Plugin: plugin.Name(), Dependency: dependency}
+			}
+		}
+	}
+
+	return sortPlugins(plugins)
+}
+
+// sortPlugins orders the plugins so that each comes after those of its dependencies that are among them, keeping the
+// supplied order otherwise. Dependencies that aren't among the plugins are ignored.
+func sortPlugins(plugins []Plugin) ([]Plugin, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	byName := make(map[string]Plugin, len(plugins))
+	for _, plugin := range plugins {
+		byName[plugin.Name()] = plugin
+	}
+
+	order := make([]Plugin, 0, len(plugins))
+	states := make(map[string]int, len(plugins))
+	var path []string
+
+	var visit func(plugin Plugin) error
+	visit = func(plugin Plugin) error {
+		name := plugin.Name()
+
+		switch states[name] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for index, entry := range path {
+				if entry == name {
+					start = index
+				}
+			}
+			cycle := append(append([]string(nil), path[start:]...), name)
+			return &DependencyCycleError{Cycle: cycle}
+		}
+
+		states[name] = visiting
+		path = append(path, name)
+
+		for _, dependency := range pluginDependencies(plugin) {
+			if next, ok := byName[dependency]; ok {
+				if err := visit(next); err != nil {
+					return err
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		states[name] = visited
+		order = append(order, plugin)
+
+		return nil
+	}
+
+	for _, plugin := range plugins {
+		if err := visit(plugin); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// dependents returns the sorted names of the loaded plugins that depend on the named plugin.
+func (b *Bot) dependents(name string) []string {
+	var names []string
+	for pluginName, plugin := range b.loadedPlugins() {
+		for _, dependency := range pluginDependencies(plugin) {
+			if dependency == name && pluginName != name {
+				names = append(names, pluginName)
+				break
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// closeOrder returns the names of the loaded plugins ordered so that each comes before the plugins it depends on.
+func (b *Bot) closeOrder() []string {
+	loaded := b.loadedPlugins()
+	names := make([]string, 0, len(loaded))
+	for name := range loaded {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	plugins := make([]Plugin, 0, len(names))
+	for _, name := range names {
+		plugins = append(plugins, loaded[name])
+	}
+
+	// Loaded plugins can't depend on each other in a cycle, since each was added after its dependencies.
+	order, err := sortPlugins(plugins)
+	if err != nil {
+		order = plugins
+	}
+
+	closing := make([]string, 0, len(order))
+	for index := len(order) - 1; index >= 0; index-- {
+		closing = append(closing, order[index].Name())
+	}
+
+	return closing
+}
@@ -0,0 +1,75 @@
+package eris_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+// dependentPlugin is a test plugin that depends on other plugins.
+type dependentPlugin struct {
+	*testPlugin
+	dependencies []string
+}
+
+func (p *dependentPlugin) Dependencies() []string {
+	return p.dependencies
+}
+
+func TestAddPlugins(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+	before := len(server.Requests())
+
+	// The leaderboard is supplied first, but has to be added after the game it ranks.
+	leaderboard := &dependentPlugin{
+		testPlugin: &testPlugin{name: "Leaderboard", commands: map[string]*discordgo.ApplicationCommand{
+			"top": chatCommand("top", "Shows the best players"),
+		}},
+		dependencies: []string{"Game"},
+	}
+	game := &testPlugin{name: "Game", commands: map[string]*discordgo.ApplicationCommand{
+		"play": chatCommand("play", "Starts a game"),
+	}}
+	echo := &testPlugin{name: "Echo", commands: map[string]*discordgo.ApplicationCommand{
+		"echo": chatCommand("echo", "Echoes a message"),
+	}}
+	if err := bot.AddPlugins(leaderboard, game, echo); err != nil {
+		t.Fatalf("failed to add plugins: %v", err)
+	}
+
+	if got, want := sortedNames(server.Commands("")), []string{"config", "echo", "play", "plugins", "top"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("global commands = %v, want %v", got, want)
+	}
+
+	// The batch is synchronized at once: one request for the registered commands and one bulk overwrite.
+	var syncs []string
+	for _, request := range server.Requests()[before:] {
+		if strings.HasSuffix(request.Path, "/commands") {
+			syncs = append(syncs, request.Method)
+		}
+	}
+	if want := []string{"GET", "PUT"}; !reflect.DeepEqual(syncs, want) {
+		t.Errorf("command requests = %v, want %v", syncs, want)
+	}
+}
+
+func TestAddPluginsMissingDependency(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	leaderboard := &dependentPlugin{testPlugin: &testPlugin{name: "Leaderboard"}, dependencies: []string{"Game"}}
+	err := bot.AddPlugins(&testPlugin{name: "Echo"}, leaderboard)
+	if _, ok := err.(*eris.MissingDependencyError); !ok {
+		t.Fatalf("AddPlugins returned %v, want a MissingDependencyError", err)
+	}
+
+	interaction := server.InteractionCreate(eristest.SlashCommand("500", "plugins"))
+	if response := server.WaitForResponse(interaction.ID); strings.Contains(response.Data.Content, "Echo") {
+		t.Errorf("plugin list %q contains a plugin of the rejected batch", response.Data.Content)
+	}
+}
@@ -0,0 +1,173 @@
+package eristest
+
+import (
+	"sort"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// SlashCommand builds a chat input command interaction invoked by the user in a DM. Use InGuild to move it into a
+// guild, and InteractionCreate to send it.
+func SlashCommand(userId string, name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.Interaction {
+	return &discordgo.Interaction{
+		Type: discordgo.InteractionApplicationCommand,
+		Data: discordgo.ApplicationCommandInteractionData{
+			Name:        name,
+			CommandType: discordgo.ChatApplicationCommand,
+			Options:     options,
+		},
+		User:   &discordgo.User{ID: userId, Username: userId},
+		Locale: discordgo.EnglishUS,
+	}
+}
+
+// Autocomplete builds an autocomplete interaction for the command, as sent while the user types. Mark the option being
+// typed in with Focused.
+func Autocomplete(userId string, name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.Interaction {
+	interaction := SlashCommand(userId, name, options...)
+	interaction.Type = discordgo.InteractionApplicationCommandAutocomplete
+
+	return interaction
+}
+
+// Focused marks an option built with Option as the one the user is typing in.
+func Focused(option *discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	option.Focused = true
+	return option
+}
+
+// SubCommand builds a sub command option for use with SlashCommand.
+func SubCommand(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:    name,
+		Type:    discordgo.ApplicationCommandOptionSubCommand,
+		Options: options,
+	}
+}
+
+// Option builds a command option for use with SlashCommand. Numeric values are sent as json numbers, so they arrive
+// in handlers as float64 just like they do from Discord.
+func Option(name string, optionType discordgo.ApplicationCommandOptionType, value any) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  name,
+		Type:  optionType,
+		Value: value,
+	}
+}
+
+// Component builds a message component interaction, such as a button press, made by the user in a DM.
+func Component(userId string, customId string, values ...string) *discordgo.Interaction {
+	componentType := discordgo.ButtonComponent
+	if len(values) > 0 {
+		componentType = discordgo.SelectMenuComponent
+	}
+
+	return &discordgo.Interaction{
+		Type: discordgo.InteractionMessageComponent,
+		Data: discordgo.MessageComponentInteractionData{
+			CustomID:      customId,
+			ComponentType: componentType,
+			Values:        values,
+		},
+		User:   &discordgo.User{ID: userId, Username: userId},
+		Locale: discordgo.EnglishUS,
+	}
+}
+
+// ModalSubmit builds the submission of a modal made by the user in a DM, with the values of its text inputs keyed by
+// their CustomID.
+func ModalSubmit(userId string, customId string, values map[string]string) *discordgo.Interaction {
+	customIds := make([]string, 0, len(values))
+	for inputId := range values {
+		customIds = append(customIds, inputId)
+	}
+	sort.Strings(customIds)
+
+	components := make([]discordgo.MessageComponent, 0, len(values))
+	for _, inputId := range customIds {
+		components = append(components, &discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				&discordgo.TextInput{CustomID: inputId, Value: values[inputId]},
+			},
+		})
+	}
+
+	return &discordgo.Interaction{
+		Type: discordgo.InteractionModalSubmit,
+		Data: discordgo.ModalSubmitInteractionData{
+			CustomID:   customId,
+			Components: components,
+		},
+		User:   &discordgo.User{ID: userId, Username: userId},
+		Locale: discordgo.EnglishUS,
+	}
+}
+
+// InGuild moves an interaction into a guild channel, turning its User into a Member as Discord does.
+func InGuild(interaction *discordgo.Interaction, guildId string, channelId string) *discordgo.Interaction {
+	interaction.GuildID = guildId
+	interaction.ChannelID = channelId
+	if interaction.User != nil {
+		interaction.Member = &discordgo.Member{GuildID: guildId, User: interaction.User}
+		interaction.User = nil
+	}
+
+	return interaction
+}
+
+// InteractionCreate assigns the interaction an id and token and dispatches it to the bot. The interaction is returned
+// so its id and token can be used to look up responses.
+func (s *Server) InteractionCreate(interaction *discordgo.Interaction) *discordgo.Interaction {
+	s.tb.Helper()
+
+	s.mu.Lock()
+	if interaction.ID == "" {
+		interaction.ID = s.id()
+	}
+	if interaction.Token == "" {
+		interaction.Token = "token-" + interaction.ID
+	}
+	interaction.AppID = s.AppId
+	interaction.Version = 1
+	s.mu.Unlock()
+
+	var payload any = interaction
+	if data, ok := interaction.Data.(discordgo.ModalSubmitInteractionData); ok {
+		payload = modalSubmitPayload{Interaction: interaction, Data: modalSubmitData(data)}
+	}
+
+	if err := s.Dispatch("INTERACTION_CREATE", payload); err != nil {
+		s.tb.Fatalf("eristest: failed to dispatch interaction: %v", err)
+	}
+
+	return interaction
+}
+
+// MessageCreate assigns the message an id and dispatches it to the bot.
+func (s *Server) MessageCreate(message *discordgo.Message) *discordgo.Message {
+	s.tb.Helper()
+
+	s.mu.Lock()
+	if message.ID == "" {
+		message.ID = s.id()
+	}
+	s.mu.Unlock()
+
+	if err := s.Dispatch("MESSAGE_CREATE", message); err != nil {
+		s.tb.Fatalf("eristest: failed to dispatch message: %v", err)
+	}
+
+	return message
+}
+
+// modalSubmitData is ModalSubmitInteractionData with its components marshaled, which discordgo leaves out.
+type modalSubmitData struct {
+	CustomID   string                       `json:"custom_id"`
+	Components []discordgo.MessageComponent `json:"components"`
+}
+
+// modalSubmitPayload is a modal submit interaction as Discord sends it.
+type modalSubmitPayload struct {
+	*discordgo.Interaction
+	Data modalSubmitData `json:"data"`
+}
@@ -0,0 +1,264 @@
+package eristest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gorilla/websocket"
+)
+
+// heartbeatInterval is sent in the Hello packet, in milliseconds. It is long enough that heartbeats never get in the
+// way of a test.
+const heartbeatInterval = 45000
+
+// closeDisallowedIntents is the close code Discord uses when a client identifies with privileged intents that aren't
+// enabled for the application.
+const closeDisallowedIntents = 4014
+
+type gatewayPayload struct {
+	Op       int             `json:"op"`
+	Data     json.RawMessage `json:"d,omitempty"`
+	Sequence int64           `json:"s,omitempty"`
+	Type     string          `json:"t,omitempty"`
+}
+
+type identifyData struct {
+	Token   string           `json:"token"`
+	Intents discordgo.Intent `json:"intents"`
+	Shard   *[2]int          `json:"shard"`
+}
+
+// gatewayConn is a single client connected to the fake gateway.
+type gatewayConn struct {
+	ws       *websocket.Conn
+	mu       sync.Mutex
+	identify identifyData
+}
+
+func (c *gatewayConn) send(payload gatewayPayload) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ws.WriteJSON(payload)
+}
+
+func (c *gatewayConn) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = c.ws.Close()
+}
+
+// serveGateway speaks just enough of the gateway protocol to get a discordgo.Session connected: Hello, Identify or
+// Resume, Ready or Resumed, and heartbeat acknowledgements.
+func (s *Server) serveGateway(w http.ResponseWriter, req *http.Request) {
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	ws, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+
+	conn := &gatewayConn{ws: ws}
+	defer func() {
+		s.removeConn(conn)
+		conn.close()
+	}()
+
+	hello, _ := json.Marshal(map[string]any{"heartbeat_interval": heartbeatInterval})
+	if err = conn.send(gatewayPayload{Op: 10, Data: hello}); err != nil {
+		return
+	}
+
+	for {
+		var payload gatewayPayload
+		if err = ws.ReadJSON(&payload); err != nil {
+			return
+		}
+
+		switch payload.Op {
+		case 1:
+			// Heartbeat
+			_ = conn.send(gatewayPayload{Op: 11})
+		case 2:
+			// Identify
+			if err = json.Unmarshal(payload.Data, &conn.identify); err != nil {
+				return
+			}
+			s.mu.Lock()
+			disallowed := conn.identify.Intents & s.disallowedIntents
+			s.mu.Unlock()
+			if disallowed != 0 {
+				message := websocket.FormatCloseMessage(closeDisallowedIntents, "Disallowed intent(s).")
+				_ = ws.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+				return
+			}
+			if err = s.ready(conn, "READY"); err != nil {
+				return
+			}
+		case 6:
+			// Resume
+			if err = s.ready(conn, "RESUMED"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ready registers the connection and sends it the READY or RESUMED dispatch.
+func (s *Server) ready(conn *gatewayConn, eventType string) error {
+	s.mu.Lock()
+	s.conns = append(s.conns, conn)
+	s.sequence++
+	sequence := s.sequence
+	data, err := json.Marshal(&discordgo.Ready{
+		Version:     9,
+		SessionID:   fmt.Sprintf("eristest-%d", sequence),
+		User:        s.botUser(),
+		Shard:       conn.identify.Shard,
+		Application: &discordgo.Application{ID: s.AppId},
+		Guilds:      []*discordgo.Guild{},
+	})
+	s.notify()
+	s.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	return conn.send(gatewayPayload{Op: 0, Type: eventType, Sequence: sequence, Data: data})
+}
+
+func (s *Server) removeConn(conn *gatewayConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for index, c := range s.conns {
+		if c == conn {
+			s.conns = append(s.conns[:index:index], s.conns[index+1:]...)
+			break
+		}
+	}
+	s.notify()
+}
+
+// Connections returns the number of clients currently identified with the gateway.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.conns)
+}
+
+// Intents returns the intents each connected client identified with.
+func (s *Server) Intents() []discordgo.Intent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	intents := make([]discordgo.Intent, 0, len(s.conns))
+	for _, conn := range s.conns {
+		intents = append(intents, conn.identify.Intents)
+	}
+
+	return intents
+}
+
+// DisallowIntents makes the gateway reject clients that identify with any of the supplied intents with close code
+// 4014, as Discord does for privileged intents that haven't been enabled for the application.
+func (s *Server) DisallowIntents(intents discordgo.Intent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.disallowedIntents = intents
+}
+
+// SetRecommendedShards changes the shard count and identify concurrency returned by /gateway/bot. Both default to 1.
+func (s *Server) SetRecommendedShards(shards int, maxConcurrency int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.shards = shards
+	s.maxConcurrency = maxConcurrency
+}
+
+// Shards returns the [shard id, shard count] pair each connected client identified with. Clients that didn't identify
+// as a shard are reported as [0, 1].
+func (s *Server) Shards() [][2]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shards := make([][2]int, 0, len(s.conns))
+	for _, conn := range s.conns {
+		shards = append(shards, conn.shard())
+	}
+
+	return shards
+}
+
+// shard returns the [shard id, shard count] pair the client identified with.
+func (c *gatewayConn) shard() [2]int {
+	if c.identify.Shard == nil {
+		return [2]int{0, 1}
+	}
+
+	return *c.identify.Shard
+}
+
+// receives returns whether the client is the shard responsible for the guild, as Discord computes it. Events outside of
+// a guild, such as direct messages, are only sent to shard 0.
+func (c *gatewayConn) receives(guildId string) bool {
+	shard := c.shard()
+	if guildId == "" {
+		return shard[0] == 0
+	}
+
+	id, err := strconv.ParseUint(guildId, 10, 64)
+	if err != nil {
+		return true
+	}
+
+	return int((id>>22)%uint64(shard[1])) == shard[0]
+}
+
+// Dispatch sends an event of the given type (for example "MESSAGE_CREATE") to every connected client. When the bot is
+// sharded, events are only sent to the shard responsible for their guild_id.
+func (s *Server) Dispatch(eventType string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var target struct {
+		GuildId string `json:"guild_id"`
+	}
+	_ = json.Unmarshal(raw, &target)
+
+	s.mu.Lock()
+	conns := append([]*gatewayConn(nil), s.conns...)
+	s.mu.Unlock()
+
+	if len(conns) == 0 {
+		return fmt.Errorf("eristest: no clients connected to the gateway")
+	}
+
+	for _, conn := range conns {
+		if !conn.receives(target.GuildId) {
+			continue
+		}
+
+		s.mu.Lock()
+		s.sequence++
+		sequence := s.sequence
+		s.mu.Unlock()
+
+		if err = conn.send(gatewayPayload{Op: 0, Type: eventType, Sequence: sequence, Data: raw}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package eristest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+// testPlugin is a plugin whose commands, routes and intents are set by the test.
+type testPlugin struct {
+	commands map[string]*discordgo.ApplicationCommand
+	routes   map[string]eris.HandlerFunc
+	intents  []discordgo.Intent
+}
+
+func (p testPlugin) Name() string {
+	return "Test"
+}
+
+func (p testPlugin) Description() string {
+	return "Test plugin"
+}
+
+func (p testPlugin) Handlers() map[string]any {
+	return nil
+}
+
+func (p testPlugin) Commands() map[string]*discordgo.ApplicationCommand {
+	return p.commands
+}
+
+func (p testPlugin) Routes() map[string]eris.HandlerFunc {
+	return p.routes
+}
+
+func (p testPlugin) Intents() []discordgo.Intent {
+	return p.intents
+}
+
+func newBot(t *testing.T, server *eristest.Server, config eris.Config) *eris.Bot {
+	t.Helper()
+
+	bot, err := server.NewBot(config, nil)
+	if err != nil {
+		t.Fatalf("failed to create bot: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = bot.Stop()
+	})
+
+	return bot
+}
+
+func TestGatewayDispatch(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newBot(t, server, eris.Config{})
+
+	if connections := server.Connections(); connections != 1 {
+		t.Fatalf("connections = %d, want 1", connections)
+	}
+
+	received := make(chan *discordgo.MessageCreate, 1)
+	bot.AddHandler("messages", func(_ *discordgo.Session, message *discordgo.MessageCreate) {
+		received <- message
+	})
+
+	sent := server.MessageCreate(&discordgo.Message{ChannelID: "400", Content: "hello", Author: &discordgo.User{ID: "500"}})
+
+	select {
+	case message := <-received:
+		if message.ID != sent.ID || message.Content != "hello" {
+			t.Errorf("received message = %+v, want %+v", message.Message, sent)
+		}
+	case <-time.After(server.Timeout):
+		t.Fatal("message was never dispatched")
+	}
+
+	plugin := testPlugin{
+		commands: map[string]*discordgo.ApplicationCommand{
+			"ping": {Name: "ping", Description: "Replies with pong"},
+		},
+		routes: map[string]eris.HandlerFunc{
+			"ping": func(r *eris.Request) error {
+				return r.Respond().Message("pong").Send()
+			},
+		},
+	}
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	interaction := server.InteractionCreate(eristest.SlashCommand("500", "ping"))
+	if response := server.WaitForResponse(interaction.ID); response.Data.Content != "pong" {
+		t.Errorf("response = %q, want pong", response.Data.Content)
+	}
+}
+
+func TestGatewayShardRouting(t *testing.T) {
+	server := eristest.NewServer(t)
+	server.SetRecommendedShards(2, 2)
+	bot := newBot(t, server, eris.Config{})
+
+	shards := server.Shards()
+	if len(shards) != 2 {
+		t.Fatalf("shards = %v, want 2", shards)
+	}
+	seen := make(map[[2]int]bool)
+	for _, shard := range shards {
+		seen[shard] = true
+	}
+	if !seen[[2]int{0, 2}] || !seen[[2]int{1, 2}] {
+		t.Fatalf("shards = %v, want [0 2] and [1 2]", shards)
+	}
+
+	type delivery struct {
+		content string
+		shardId int
+	}
+	received := make(chan delivery, 3)
+	bot.AddHandler("messages", func(session *discordgo.Session, message *discordgo.MessageCreate) {
+		received <- delivery{content: message.Content, shardId: session.ShardID}
+	})
+
+	// Guilds are assigned to shards by (guild_id >> 22) % shard count.
+	for _, message := range []*discordgo.Message{
+		{GuildID: "4194304", ChannelID: "400", Content: "odd"},
+		{GuildID: "8388608", ChannelID: "400", Content: "even"},
+		{ChannelID: "401", Content: "direct"},
+	} {
+		server.MessageCreate(message)
+	}
+
+	want := map[string]int{"odd": 1, "even": 0, "direct": 0}
+	for range want {
+		select {
+		case delivery := <-received:
+			if shardId, ok := want[delivery.content]; !ok || delivery.shardId != shardId {
+				t.Errorf("%s received by shard %d, want %d", delivery.content, delivery.shardId, shardId)
+			}
+		case <-time.After(server.Timeout):
+			t.Fatal("message was never dispatched")
+		}
+	}
+
+	// Each message is only received by the shard responsible for its guild.
+	select {
+	case delivery := <-received:
+		t.Errorf("%s received again by shard %d", delivery.content, delivery.shardId)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestGatewayDisallowIntents(t *testing.T) {
+	server := eristest.NewServer(t)
+	server.DisallowIntents(discordgo.IntentMessageContent)
+	bot := newBot(t, server, eris.Config{DeferConnect: true})
+
+	if err := bot.AddPlugin(testPlugin{intents: []discordgo.Intent{discordgo.IntentMessageContent}}); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	err := bot.Start()
+
+	var intentsErr *eris.DisallowedIntentsError
+	if !errors.As(err, &intentsErr) {
+		t.Fatalf("start error = %v, want a DisallowedIntentsError", err)
+	}
+	if intentsErr.Intents != discordgo.IntentMessageContent {
+		t.Errorf("disallowed intents = %d, want %d", intentsErr.Intents, discordgo.IntentMessageContent)
+	}
+	if len(intentsErr.Plugins) != 1 || intentsErr.Plugins[0] != "Test" {
+		t.Errorf("plugins = %v, want [Test]", intentsErr.Plugins)
+	}
+	if connections := server.Connections(); connections != 0 {
+		t.Errorf("connections = %d, want 0", connections)
+	}
+}
+
+func TestGatewayIntents(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newBot(t, server, eris.Config{DeferConnect: true})
+
+	if err := bot.AddPlugin(testPlugin{intents: []discordgo.Intent{discordgo.IntentGuildMessages}}); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+	if err := bot.Start(); err != nil {
+		t.Fatalf("failed to start bot: %v", err)
+	}
+
+	intents := server.Intents()
+	if len(intents) != 1 || intents[0]&discordgo.IntentGuildMessages == 0 {
+		t.Errorf("identified intents = %v, want guild messages included", intents)
+	}
+}
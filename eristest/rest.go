@@ -0,0 +1,363 @@
+package eristest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// serveREST routes a REST request to the matching fake endpoint. Only the endpoints eris and its plugins rely on are
+// implemented, anything else is answered with 404.
+func (s *Server) serveREST(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/api/v"+discordgo.APIVersion)
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.notify()
+
+	s.requests = append(s.requests, Request{Method: req.Method, Path: path})
+
+	body, _ := io.ReadAll(req.Body)
+
+	match := func(method string, pattern ...string) bool {
+		if req.Method != method || len(pattern) != len(segments) {
+			return false
+		}
+		for index, segment := range pattern {
+			if segment != "*" && segment != segments[index] {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case match(http.MethodGet, "gateway"):
+		writeJSON(w, http.StatusOK, map[string]any{"url": s.gatewayURL()})
+	case match(http.MethodGet, "gateway", "bot"):
+		writeJSON(w, http.StatusOK, &discordgo.GatewayBotResponse{
+			URL:    s.gatewayURL(),
+			Shards: s.shards,
+			SessionStartLimit: discordgo.SessionInformation{
+				Total:          1000,
+				Remaining:      1000,
+				MaxConcurrency: s.maxConcurrency,
+			},
+		})
+
+	// Application commands
+	case match(http.MethodGet, "applications", "*", "commands"):
+		writeJSON(w, http.StatusOK, s.commandsOrEmpty(""))
+	case match(http.MethodGet, "applications", "*", "guilds", "*", "commands"):
+		writeJSON(w, http.StatusOK, s.commandsOrEmpty(segments[3]))
+	case match(http.MethodPut, "applications", "*", "commands"):
+		s.overwriteCommands(w, "", body)
+	case match(http.MethodPut, "applications", "*", "guilds", "*", "commands"):
+		s.overwriteCommands(w, segments[3], body)
+	case match(http.MethodPost, "applications", "*", "commands"):
+		s.createCommand(w, "", body)
+	case match(http.MethodPost, "applications", "*", "guilds", "*", "commands"):
+		s.createCommand(w, segments[3], body)
+	case match(http.MethodDelete, "applications", "*", "commands", "*"):
+		s.deleteCommand(w, "", segments[3])
+	case match(http.MethodDelete, "applications", "*", "guilds", "*", "commands", "*"):
+		s.deleteCommand(w, segments[3], segments[5])
+
+	// Interactions
+	case match(http.MethodPost, "interactions", "*", "*", "callback"):
+		response, err := decodeResponse(body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body: "+err.Error())
+			return
+		}
+		for _, existing := range s.responses {
+			if existing.InteractionId == segments[1] {
+				writeError(w, http.StatusBadRequest, 40060, "Interaction has already been acknowledged.")
+				return
+			}
+		}
+		s.responses = append(s.responses, InteractionResponse{
+			InteractionId: segments[1],
+			Token:         segments[2],
+			Response:      response,
+		})
+		w.WriteHeader(http.StatusNoContent)
+	case match(http.MethodPatch, "webhooks", "*", "*", "messages", "@original"):
+		message := s.originals[segments[2]]
+		if message == nil {
+			message = &discordgo.Message{ID: s.id(), Author: s.botUser(), Timestamp: time.Now()}
+			s.originals[segments[2]] = message
+		}
+		if !applyEdit(w, body, message) {
+			return
+		}
+		writeJSON(w, http.StatusOK, message)
+	case match(http.MethodDelete, "webhooks", "*", "*", "messages", "@original"):
+		delete(s.originals, segments[2])
+		w.WriteHeader(http.StatusNoContent)
+	case match(http.MethodPost, "webhooks", "*", "*"):
+		message := &discordgo.Message{}
+		if !decodeJSON(w, body, message) {
+			return
+		}
+		message.ID, message.Author, message.Timestamp = s.id(), s.botUser(), time.Now()
+		s.followups[segments[2]] = append(s.followups[segments[2]], message)
+		writeJSON(w, http.StatusOK, message)
+	case match(http.MethodPatch, "webhooks", "*", "*", "messages", "*"):
+		message := findMessage(s.followups[segments[2]], segments[4])
+		if message == nil {
+			writeError(w, http.StatusNotFound, 10008, "Unknown Message")
+			return
+		}
+		if !applyEdit(w, body, message) {
+			return
+		}
+		writeJSON(w, http.StatusOK, message)
+	case match(http.MethodDelete, "webhooks", "*", "*", "messages", "*"):
+		s.followups[segments[2]] = removeMessage(s.followups[segments[2]], segments[4])
+		w.WriteHeader(http.StatusNoContent)
+
+	// Channels and messages
+	case match(http.MethodPost, "users", "@me", "channels"):
+		var params struct {
+			RecipientId string `json:"recipient_id"`
+		}
+		if !decodeJSON(w, body, &params) {
+			return
+		}
+		channelId, ok := s.dms[params.RecipientId]
+		if !ok {
+			channelId = s.id()
+			s.dms[params.RecipientId] = channelId
+		}
+		writeJSON(w, http.StatusOK, &discordgo.Channel{
+			ID:         channelId,
+			Type:       discordgo.ChannelTypeDM,
+			Recipients: []*discordgo.User{{ID: params.RecipientId}},
+		})
+	case match(http.MethodPost, "channels", "*", "messages"):
+		message := &discordgo.Message{}
+		if !decodeJSON(w, body, message) {
+			return
+		}
+		message.ID, message.ChannelID, message.Author, message.Timestamp = s.id(), segments[1], s.botUser(), time.Now()
+		s.channels[segments[1]] = append(s.channels[segments[1]], message)
+		writeJSON(w, http.StatusOK, message)
+	case match(http.MethodPatch, "channels", "*", "messages", "*"):
+		message := findMessage(s.channels[segments[1]], segments[3])
+		if message == nil {
+			writeError(w, http.StatusNotFound, 10008, "Unknown Message")
+			return
+		}
+		if !applyEdit(w, body, message) {
+			return
+		}
+		writeJSON(w, http.StatusOK, message)
+	case match(http.MethodDelete, "channels", "*", "messages", "*"):
+		s.channels[segments[1]] = removeMessage(s.channels[segments[1]], segments[3])
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, http.StatusNotFound, 0, "eristest: unsupported endpoint "+req.Method+" "+path)
+	}
+}
+
+func (s *Server) commandsOrEmpty(guildId string) []*discordgo.ApplicationCommand {
+	if commands := s.commands[guildId]; commands != nil {
+		return commands
+	}
+
+	return []*discordgo.ApplicationCommand{}
+}
+
+func (s *Server) overwriteCommands(w http.ResponseWriter, guildId string, body []byte) {
+	var commands []*discordgo.ApplicationCommand
+	if !decodeJSON(w, body, &commands) {
+		return
+	}
+
+	// Discord keeps the ids of commands that survive an overwrite.
+	for _, command := range commands {
+		if command.Type == 0 {
+			command.Type = discordgo.ChatApplicationCommand
+		}
+		for _, existing := range s.commands[guildId] {
+			if existing.Name == command.Name && existing.Type == command.Type {
+				command.ID = existing.ID
+			}
+		}
+		s.normalizeCommand(command, guildId)
+	}
+	s.commands[guildId] = commands
+
+	writeJSON(w, http.StatusOK, commands)
+}
+
+func (s *Server) createCommand(w http.ResponseWriter, guildId string, body []byte) {
+	var command discordgo.ApplicationCommand
+	if !decodeJSON(w, body, &command) {
+		return
+	}
+
+	s.normalizeCommand(&command, guildId)
+
+	// Creating a command with an existing name and type overwrites it.
+	commands := s.commands[guildId]
+	for index, existing := range commands {
+		if existing.Name == command.Name && existing.Type == command.Type {
+			commands[index] = &command
+			writeJSON(w, http.StatusOK, &command)
+			return
+		}
+	}
+	s.commands[guildId] = append(commands, &command)
+
+	writeJSON(w, http.StatusCreated, &command)
+}
+
+func (s *Server) deleteCommand(w http.ResponseWriter, guildId string, commandId string) {
+	commands := s.commands[guildId]
+	for index, command := range commands {
+		if command.ID == commandId {
+			s.commands[guildId] = append(commands[:index:index], commands[index+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, 10063, "Unknown application command")
+}
+
+// normalizeCommand fills in the fields Discord assigns to a registered command.
+func (s *Server) normalizeCommand(command *discordgo.ApplicationCommand, guildId string) {
+	if command.ID == "" {
+		command.ID = s.id()
+	}
+	if command.Type == 0 {
+		command.Type = discordgo.ChatApplicationCommand
+	}
+	command.ApplicationID = s.AppId
+	command.GuildID = guildId
+	command.Version = s.id()
+}
+
+func (s *Server) botUser() *discordgo.User {
+	return &discordgo.User{ID: s.BotUserId, Username: s.BotName, Bot: true}
+}
+
+func findMessage(messages []*discordgo.Message, id string) *discordgo.Message {
+	for _, message := range messages {
+		if message.ID == id {
+			return message
+		}
+	}
+
+	return nil
+}
+
+func removeMessage(messages []*discordgo.Message, id string) []*discordgo.Message {
+	for index, message := range messages {
+		if message.ID == id {
+			return append(messages[:index:index], messages[index+1:]...)
+		}
+	}
+
+	return messages
+}
+
+// decodeResponse decodes an interaction callback. discordgo can marshal but not unmarshal the components of an
+// InteractionResponseData, so they are decoded separately.
+func decodeResponse(body []byte) (*discordgo.InteractionResponse, error) {
+	type responseData discordgo.InteractionResponseData
+	var raw struct {
+		Type discordgo.InteractionResponseType `json:"type"`
+		Data *struct {
+			responseData
+			Components json.RawMessage `json:"components"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+
+	response := &discordgo.InteractionResponse{Type: raw.Type}
+	if raw.Data != nil {
+		data := discordgo.InteractionResponseData(raw.Data.responseData)
+		components, err := decodeComponents(raw.Data.Components)
+		if err != nil {
+			return nil, err
+		}
+		data.Components = components
+		response.Data = &data
+	}
+
+	return response, nil
+}
+
+// applyEdit applies a message or webhook edit to an existing message, leaving omitted fields untouched.
+func applyEdit(w http.ResponseWriter, body []byte, message *discordgo.Message) bool {
+	var edit struct {
+		Content    *string                    `json:"content"`
+		Embeds     *[]*discordgo.MessageEmbed `json:"embeds"`
+		Components json.RawMessage            `json:"components"`
+	}
+	if !decodeJSON(w, body, &edit) {
+		return false
+	}
+
+	if edit.Content != nil {
+		message.Content = *edit.Content
+	}
+	if edit.Embeds != nil {
+		message.Embeds = *edit.Embeds
+	}
+	if edit.Components != nil {
+		components, err := decodeComponents(edit.Components)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body: "+err.Error())
+			return false
+		}
+		message.Components = components
+	}
+
+	return true
+}
+
+// decodeComponents decodes a json array of message components by way of discordgo.Message, which knows how to
+// unmarshal them.
+func decodeComponents(raw json.RawMessage) ([]discordgo.MessageComponent, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var message discordgo.Message
+	if err := json.Unmarshal([]byte(`{"components":`+string(raw)+`}`), &message); err != nil {
+		return nil, err
+	}
+
+	return message.Components, nil
+}
+
+func decodeJSON(w http.ResponseWriter, body []byte, v any) bool {
+	if err := json.Unmarshal(body, v); err != nil {
+		writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body: "+err.Error())
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code int, message string) {
+	writeJSON(w, status, map[string]any{"code": code, "message": message})
+}
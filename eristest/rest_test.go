@@ -0,0 +1,158 @@
+package eristest_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris/eristest"
+)
+
+func TestRESTRecordsRequests(t *testing.T) {
+	server := eristest.NewServer(t)
+	session := server.Session()
+
+	if _, err := session.ChannelMessageSend("400", "hello"); err != nil {
+		t.Fatalf("failed to send message: %v", err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("requests = %+v, want 1", requests)
+	}
+	if want := (eristest.Request{Method: http.MethodPost, Path: "/channels/400/messages"}); requests[0] != want {
+		t.Errorf("request = %+v, want %+v", requests[0], want)
+	}
+
+	messages := server.ChannelMessages("400")
+	if len(messages) != 1 || messages[0].Content != "hello" || messages[0].Author.ID != server.BotUserId {
+		t.Errorf("channel messages = %+v, want one from the bot saying hello", messages)
+	}
+}
+
+func TestRESTDirectMessages(t *testing.T) {
+	server := eristest.NewServer(t)
+	session := server.Session()
+
+	channel, err := session.UserChannelCreate("500")
+	if err != nil {
+		t.Fatalf("failed to create DM channel: %v", err)
+	}
+	if _, err = session.ChannelMessageSend(channel.ID, "psst"); err != nil {
+		t.Fatalf("failed to send message: %v", err)
+	}
+
+	again, err := session.UserChannelCreate("500")
+	if err != nil {
+		t.Fatalf("failed to create DM channel again: %v", err)
+	}
+	if again.ID != channel.ID {
+		t.Errorf("DM channel = %s, want %s again", again.ID, channel.ID)
+	}
+
+	messages := server.WaitForDirectMessages("500", 1)
+	if messages[0].Content != "psst" {
+		t.Errorf("direct message = %q, want %q", messages[0].Content, "psst")
+	}
+}
+
+func TestRESTCommands(t *testing.T) {
+	server := eristest.NewServer(t)
+	session := server.Session()
+
+	server.SetCommands("", &discordgo.ApplicationCommand{Name: "stale", Description: "Left over"})
+
+	created, err := session.ApplicationCommandCreate(server.AppId, "300", &discordgo.ApplicationCommand{
+		Name:        "poll",
+		Description: "Starts a poll",
+	})
+	if err != nil {
+		t.Fatalf("failed to create command: %v", err)
+	}
+	if created.ID == "" || created.GuildID != "300" || created.ApplicationID != server.AppId {
+		t.Errorf("created command = %+v, want an id, guild 300 and the app id", created)
+	}
+
+	overwritten, err := session.ApplicationCommandBulkOverwrite(server.AppId, "", []*discordgo.ApplicationCommand{
+		{Name: "ping", Description: "Replies with pong"},
+	})
+	if err != nil {
+		t.Fatalf("failed to overwrite commands: %v", err)
+	}
+	if len(overwritten) != 1 || overwritten[0].Name != "ping" {
+		t.Errorf("overwritten commands = %+v, want ping", overwritten)
+	}
+
+	global := server.Commands("")
+	if len(global) != 1 || global[0].Name != "ping" {
+		t.Errorf("global commands = %+v, want only ping", global)
+	}
+
+	if err = session.ApplicationCommandDelete(server.AppId, "300", created.ID); err != nil {
+		t.Fatalf("failed to delete command: %v", err)
+	}
+	if commands := server.Commands("300"); len(commands) != 0 {
+		t.Errorf("guild commands = %+v, want none", commands)
+	}
+}
+
+func TestRESTInteractionResponses(t *testing.T) {
+	server := eristest.NewServer(t)
+	session := server.Session()
+
+	interaction := &discordgo.Interaction{ID: "600", Token: "token-600", AppID: server.AppId}
+	response := &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Content: "first"},
+	}
+	if err := session.InteractionRespond(interaction, response); err != nil {
+		t.Fatalf("failed to respond: %v", err)
+	}
+
+	recorded := server.WaitForResponse("600")
+	if recorded.Type != response.Type || recorded.Data.Content != "first" {
+		t.Errorf("response = %+v, want %+v", recorded, response)
+	}
+
+	// Discord only accepts one callback per interaction.
+	err := session.InteractionRespond(interaction, response)
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Message == nil || restErr.Message.Code != 40060 {
+		t.Errorf("second response error = %v, want code 40060", err)
+	}
+
+	content := "edited"
+	if _, err = session.InteractionResponseEdit(interaction, &discordgo.WebhookEdit{Content: &content}); err != nil {
+		t.Fatalf("failed to edit response: %v", err)
+	}
+	if original := server.Original("token-600"); original == nil || original.Content != "edited" {
+		t.Errorf("original = %+v, want edited", original)
+	}
+
+	followup, err := session.FollowupMessageCreate(interaction, true, &discordgo.WebhookParams{Content: "later"})
+	if err != nil {
+		t.Fatalf("failed to create followup: %v", err)
+	}
+	if followups := server.Followups("token-600"); len(followups) != 1 || followups[0].Content != "later" {
+		t.Errorf("followups = %+v, want later", followups)
+	}
+
+	if err = session.FollowupMessageDelete(interaction, followup.ID); err != nil {
+		t.Fatalf("failed to delete followup: %v", err)
+	}
+	if followups := server.Followups("token-600"); len(followups) != 0 {
+		t.Errorf("followups = %+v, want none", followups)
+	}
+}
+
+func TestRESTUnsupportedEndpoint(t *testing.T) {
+	server := eristest.NewServer(t)
+
+	_, err := server.Session().Guild("300")
+
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Response.StatusCode != http.StatusNotFound {
+		t.Errorf("error = %v, want a 404", err)
+	}
+}
@@ -0,0 +1,322 @@
+// Package eristest provides an in-process stand-in for the Discord REST API and gateway so that eris bots and plugins
+// can be exercised with go test, without a token or network access.
+package eristest
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gorilla/websocket"
+	"github.com/olympus-go/eris"
+)
+
+const (
+	DefaultAppId     = "100000000000000001"
+	DefaultBotUserId = "100000000000000002"
+	DefaultBotName   = "eristest"
+)
+
+// Request is a REST request received by the Server.
+type Request struct {
+	Method string
+	Path   string
+}
+
+// InteractionResponse is an interaction callback received by the Server.
+type InteractionResponse struct {
+	InteractionId string
+	Token         string
+	Response      *discordgo.InteractionResponse
+}
+
+// Server is a fake Discord backend. REST requests are served in-process through the session's http.Client, while the
+// gateway is served over a local websocket so that events travel through discordgo's regular event handling.
+type Server struct {
+	AppId     string
+	BotUserId string
+	BotName   string
+
+	// Timeout is how long the Wait functions block before giving up.
+	Timeout time.Duration
+
+	tb      testing.TB
+	gateway *httptest.Server
+
+	mu                sync.Mutex
+	changed           chan struct{}
+	nextId            int64
+	sequence          int64
+	conns             []*gatewayConn
+	disallowedIntents discordgo.Intent
+	shards            int
+	maxConcurrency    int
+	requests          []Request
+	commands          map[string][]*discordgo.ApplicationCommand
+	responses         []InteractionResponse
+	originals         map[string]*discordgo.Message
+	followups         map[string][]*discordgo.Message
+	channels          map[string][]*discordgo.Message
+	dms               map[string]string
+}
+
+// NewServer starts a fake Discord backend. It is closed automatically when the test completes.
+func NewServer(tb testing.TB) *Server {
+	tb.Helper()
+
+	s := &Server{
+		AppId:          DefaultAppId,
+		BotUserId:      DefaultBotUserId,
+		BotName:        DefaultBotName,
+		Timeout:        5 * time.Second,
+		tb:             tb,
+		changed:        make(chan struct{}),
+		nextId:         200000000000000000,
+		shards:         1,
+		maxConcurrency: 1,
+		commands:       make(map[string][]*discordgo.ApplicationCommand),
+		originals:      make(map[string]*discordgo.Message),
+		followups:      make(map[string][]*discordgo.Message),
+		channels:       make(map[string][]*discordgo.Message),
+		dms:            make(map[string]string),
+	}
+
+	s.gateway = httptest.NewServer(http.HandlerFunc(s.serveGateway))
+	tb.Cleanup(s.Close)
+
+	return s
+}
+
+// Close shuts down the gateway and drops every open connection.
+func (s *Server) Close() {
+	s.mu.Lock()
+	conns := s.conns
+	s.conns = nil
+	s.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.close()
+	}
+
+	s.gateway.CloseClientConnections()
+	s.gateway.Close()
+}
+
+// Session returns a discordgo.Session that talks to the Server instead of Discord.
+func (s *Server) Session() *discordgo.Session {
+	session, _ := discordgo.New("Bot eristest")
+	session.Client = &http.Client{Transport: transport{s}}
+	session.Dialer = &websocket.Dialer{HandshakeTimeout: s.Timeout}
+	session.ShouldRetryOnRateLimit = false
+
+	return session
+}
+
+// NewBot creates an eris.Bot connected to the Server.
+func (s *Server) NewBot(config eris.Config, h slog.Handler) (*eris.Bot, error) {
+	return eris.NewBotWithSession(s.Session(), config, h)
+}
+
+// Requests returns every REST request received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// Commands returns the commands currently registered for the guild (global if empty).
+func (s *Server) Commands(guildId string) []*discordgo.ApplicationCommand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*discordgo.ApplicationCommand(nil), s.commands[guildId]...)
+}
+
+// SetCommands replaces the commands registered for the guild (global if empty), as if they were left over from a
+// previous run.
+func (s *Server) SetCommands(guildId string, commands ...*discordgo.ApplicationCommand) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, command := range commands {
+		if command.ID == "" {
+			command.ID = s.id()
+		}
+		command.ApplicationID = s.AppId
+		command.GuildID = guildId
+	}
+	s.commands[guildId] = commands
+}
+
+// Responses returns every interaction callback received so far.
+func (s *Server) Responses() []InteractionResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]InteractionResponse(nil), s.responses...)
+}
+
+// Response returns the callback sent for the interaction, if any.
+func (s *Server) Response(interactionId string) (*discordgo.InteractionResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, response := range s.responses {
+		if response.InteractionId == interactionId {
+			return response.Response, true
+		}
+	}
+
+	return nil, false
+}
+
+// Original returns the current state of the interaction's original response, including any edits made to it. It is
+// nil if the response was never edited or has been deleted.
+func (s *Server) Original(token string) *discordgo.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	message, ok := s.originals[token]
+	if !ok {
+		return nil
+	}
+
+	return copyMessages([]*discordgo.Message{message})[0]
+}
+
+// Followups returns the followup messages currently attached to the interaction token.
+func (s *Server) Followups(token string) []*discordgo.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return copyMessages(s.followups[token])
+}
+
+// ChannelMessages returns the messages currently in the channel.
+func (s *Server) ChannelMessages(channelId string) []*discordgo.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return copyMessages(s.channels[channelId])
+}
+
+// DirectMessages returns the messages currently in the bot's DM channel with the user.
+func (s *Server) DirectMessages(userId string) []*discordgo.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channelId, ok := s.dms[userId]
+	if !ok {
+		return nil
+	}
+
+	return copyMessages(s.channels[channelId])
+}
+
+// WaitFor blocks until cond returns true or Timeout elapses, re-evaluating cond every time the Server records a
+// request. It returns the final result of cond.
+func (s *Server) WaitFor(cond func() bool) bool {
+	timer := time.NewTimer(s.Timeout)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		changed := s.changed
+		s.mu.Unlock()
+
+		if cond() {
+			return true
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return cond()
+		}
+	}
+}
+
+// WaitForResponse blocks until the interaction has been responded to, failing the test if it never is.
+func (s *Server) WaitForResponse(interactionId string) *discordgo.InteractionResponse {
+	s.tb.Helper()
+
+	var response *discordgo.InteractionResponse
+	ok := s.WaitFor(func() bool {
+		response, _ = s.Response(interactionId)
+		return response != nil
+	})
+	if !ok {
+		s.tb.Fatalf("eristest: no response to interaction %s after %s", interactionId, s.Timeout)
+	}
+
+	return response
+}
+
+// WaitForDirectMessages blocks until the user has received at least n direct messages, failing the test if they never
+// do.
+func (s *Server) WaitForDirectMessages(userId string, n int) []*discordgo.Message {
+	s.tb.Helper()
+
+	var messages []*discordgo.Message
+	ok := s.WaitFor(func() bool {
+		messages = s.DirectMessages(userId)
+		return len(messages) >= n
+	})
+	if !ok {
+		s.tb.Fatalf("eristest: user %s received %d direct messages after %s, wanted %d", userId, len(messages),
+			s.Timeout, n)
+	}
+
+	return messages
+}
+
+// copyMessages returns copies of the messages, so that callers don't race with edits made to them later. s.mu must be
+// held.
+func copyMessages(messages []*discordgo.Message) []*discordgo.Message {
+	copies := make([]*discordgo.Message, 0, len(messages))
+	for _, message := range messages {
+		message := *message
+		copies = append(copies, &message)
+	}
+
+	return copies
+}
+
+// notify wakes anything blocked in WaitFor. s.mu must be held.
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// id returns a new unique snowflake. s.mu must be held.
+func (s *Server) id() string {
+	s.nextId++
+	return strconv.FormatInt(s.nextId, 10)
+}
+
+// gatewayURL returns the websocket url of the fake gateway.
+func (s *Server) gatewayURL() string {
+	return "ws" + strings.TrimPrefix(s.gateway.URL, "http")
+}
+
+// transport serves discordgo's REST requests in-process.
+type transport struct {
+	server *Server
+}
+
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	t.server.serveREST(recorder, req)
+
+	response := recorder.Result()
+	response.Request = req
+
+	return response, nil
+}
@@ -0,0 +1,155 @@
+package eris
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris/utils"
+)
+
+// handlerTarget is the function a registered handler calls, along with the plugin it belongs to.
+type handlerTarget struct {
+	plugin  string
+	handler reflect.Value
+}
+
+// handlerSlot is a handler registered on the shards. Its target can be swapped for a function of the same type without
+// registering it again, so that replacing a handler neither drops events nor handles them twice.
+type handlerSlot struct {
+	handlerType reflect.Type
+	target      atomic.Pointer[handlerTarget]
+}
+
+// wrapHandler returns a handler of the slot's type that calls the slot's current target, that is tracked as in-flight
+// while it runs, that drops events once the bot has started shutting down, and that recovers from panics.
+func (b *Bot) wrapHandler(name string, slot *handlerSlot) any {
+	return reflect.MakeFunc(slot.handlerType, func(args []reflect.Value) []reflect.Value {
+		if !b.acquire() {
+			return nil
+		}
+		defer b.inFlight.Done()
+
+		target := slot.target.Load()
+
+		defer func() {
+			if value := recover(); value != nil {
+				// The interaction may be answered by the plugin owning it, so only its owner tells the user.
+				session, i := interactionArgs(args)
+				if i == nil || !b.handlesInteraction(target.plugin, i) {
+					session, i = nil, nil
+				}
+				b.handlePanic(value, target.plugin, slog.String("handler", name), session, i)
+			}
+		}()
+
+		return target.handler.Call(args)
+	}).Interface()
+}
+
+// acquire registers a new in-flight handler call. It returns false if the bot is shutting down, in which case the
+// event should be dropped.
+func (b *Bot) acquire() bool {
+	b.closingLock.RLock()
+	defer b.closingLock.RUnlock()
+
+	if b.closing {
+		return false
+	}
+
+	b.inFlight.Add(1)
+
+	return true
+}
+
+// handlePanic logs a recovered panic along with its stack, records a failure for the plugin, and tells the user
+// something went wrong if an interaction is given, which callers only do for the handler owning it.
+func (b *Bot) handlePanic(value any, plugin string, source slog.Attr, session *discordgo.Session,
+	i *discordgo.InteractionCreate) {
+	b.Logger.Error("recovered from panic in handler",
+		slog.String("plugin", plugin),
+		source,
+		slog.String("panic", fmt.Sprint(value)),
+		slog.String("stack", string(debug.Stack())),
+	)
+
+	b.recordFailure(plugin)
+	b.metrics.panics.WithLabelValues(plugin).Inc()
+
+	if session != nil && i != nil {
+		// If the interaction was already answered this fails, which is fine.
+		_ = utils.InteractionResponse(session, i.Interaction).Ephemeral().Message("Something went wrong.").Send()
+	}
+}
+
+// handlesInteraction returns whether the plugin answers the interaction through its own handlers: it declared the
+// command, or prefixed the component's CustomID, and no route answers it instead.
+func (b *Bot) handlesInteraction(plugin string, i *discordgo.InteractionCreate) bool {
+	if plugin == "" {
+		return false
+	}
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		data := i.ApplicationCommandData()
+		path, _ := commandPath(data)
+
+		b.routesLock.RLock()
+		defer b.routesLock.RUnlock()
+
+		_, routed := b.routes[path]
+		return !routed && b.commandOwners[data.Name] == plugin
+	case discordgo.InteractionMessageComponent, discordgo.InteractionModalSubmit:
+		var customId string
+		if i.Type == discordgo.InteractionMessageComponent {
+			customId = i.MessageComponentData().CustomID
+		} else {
+			customId = i.ModalSubmitData().CustomID
+		}
+
+		if route, _ := b.matchComponent(customId); route.handler != nil {
+			return false
+		}
+		return strings.HasPrefix(customId, CustomIdPrefix(plugin))
+	default:
+		return false
+	}
+}
+
+// recordFailure counts a failed handler call against the plugin.
+func (b *Bot) recordFailure(plugin string) {
+	b.failuresLock.Lock()
+	defer b.failuresLock.Unlock()
+
+	b.failures[plugin]++
+}
+
+// Failures returns the number of handler calls that panicked or returned an error, keyed by plugin name. Failures of
+// handlers added directly through AddHandler are counted under an empty name.
+func (b *Bot) Failures() map[string]int64 {
+	b.failuresLock.Lock()
+	defer b.failuresLock.Unlock()
+
+	failures := make(map[string]int64, len(b.failures))
+	for plugin, count := range b.failures {
+		failures[plugin] = count
+	}
+
+	return failures
+}
+
+// interactionArgs returns the session and interaction a handler was called with, if it was handling an interaction.
+func interactionArgs(args []reflect.Value) (*discordgo.Session, *discordgo.InteractionCreate) {
+	if len(args) != 2 {
+		return nil, nil
+	}
+
+	session, _ := args[0].Interface().(*discordgo.Session)
+	i, _ := args[1].Interface().(*discordgo.InteractionCreate)
+
+	return session, i
+}
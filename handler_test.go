@@ -0,0 +1,60 @@
+package eris_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+// handlerPlugin is a test plugin that also registers its own event handlers.
+type handlerPlugin struct {
+	*testPlugin
+	handlers map[string]any
+}
+
+func (p *handlerPlugin) Handlers() map[string]any {
+	return p.handlers
+}
+
+func TestHandlerPanicOnlyAnswersOwnInteractions(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	// The spy sees every interaction, and leaves a message before panicking so that the test knows it ran.
+	spy := &handlerPlugin{
+		testPlugin: &testPlugin{name: "Spy"},
+		handlers: map[string]any{
+			"spy": func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+				_, _ = s.ChannelMessageSend("spy", i.ID)
+				panic("spy failed")
+			},
+		},
+	}
+	if err := bot.AddPlugin(spy); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	owned := server.InteractionCreate(eristest.Component("500", eris.CustomIdPrefix("Spy")+"peek"))
+	foreign := server.InteractionCreate(eristest.Component("500", "other_1"))
+
+	if response := server.WaitForResponse(owned.ID); response.Data.Content != "Something went wrong." {
+		t.Errorf("response to the spy's own component = %q, want its failure", response.Data.Content)
+	}
+
+	// Shutting down waits for the spy's handlers to return, after which the foreign component must still be unanswered.
+	server.WaitFor(func() bool {
+		return len(server.ChannelMessages("spy")) == 2
+	})
+	if err := bot.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down: %v", err)
+	}
+	if response, ok := server.Response(foreign.ID); ok {
+		t.Errorf("panic of a plugin not owning the component answered it with %q", response.Data.Content)
+	}
+	if failures := bot.Failures()["Spy"]; failures != 2 {
+		t.Errorf("failures = %d, want 2", failures)
+	}
+}
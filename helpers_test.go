@@ -0,0 +1,76 @@
+package eris_test
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+// testPlugin is a plugin whose declarations are set by the test.
+type testPlugin struct {
+	name       string
+	commands   map[string]*discordgo.ApplicationCommand
+	routes     map[string]eris.HandlerFunc
+	components map[string]eris.HandlerFunc
+	intents    []discordgo.Intent
+}
+
+func (p *testPlugin) Name() string {
+	return p.name
+}
+
+func (p *testPlugin) Description() string {
+	return "Test plugin " + p.name
+}
+
+func (p *testPlugin) Handlers() map[string]any {
+	return nil
+}
+
+func (p *testPlugin) Commands() map[string]*discordgo.ApplicationCommand {
+	return p.commands
+}
+
+func (p *testPlugin) Routes() map[string]eris.HandlerFunc {
+	return p.routes
+}
+
+func (p *testPlugin) Components() map[string]eris.HandlerFunc {
+	return p.components
+}
+
+func (p *testPlugin) Intents() []discordgo.Intent {
+	return p.intents
+}
+
+// newTestBot creates a bot connected to the server, which is stopped when the test completes.
+func newTestBot(t *testing.T, server *eristest.Server, config eris.Config) *eris.Bot {
+	t.Helper()
+
+	bot, err := server.NewBot(config, nil)
+	if err != nil {
+		t.Fatalf("failed to create bot: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = bot.Stop()
+	})
+
+	return bot
+}
+
+// chatCommand returns a chat input command with the name and description.
+func chatCommand(name string, description string) *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{Name: name, Description: description}
+}
+
+// commandNames returns the names of the commands in order.
+func commandNames(commands []*discordgo.ApplicationCommand) []string {
+	names := make([]string, 0, len(commands))
+	for _, command := range commands {
+		names = append(names, command.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,152 @@
+package eris
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gorilla/websocket"
+)
+
+// PrivilegedIntents are the intents that have to be enabled for the application in the Discord developer portal
+// before a bot may identify with them.
+const PrivilegedIntents = discordgo.IntentGuildMembers | discordgo.IntentGuildPresences | discordgo.IntentMessageContent
+
+// closeDisallowedIntents is the gateway close code sent when identifying with privileged intents that haven't been
+// enabled for the application.
+const closeDisallowedIntents = 4014
+
+// DisallowedIntentsError is returned when Discord rejects the bot's privileged intents. Plugins lists the loaded
+// plugins that requested any of them.
+type DisallowedIntentsError struct {
+	Intents discordgo.Intent
+	Plugins []string
+	Err     error
+}
+
+func (e *DisallowedIntentsError) Error() string {
+	message := fmt.Sprintf("discord rejected privileged intents %d; enable them in the developer portal", e.Intents)
+	if len(e.Plugins) > 0 {
+		message += fmt.Sprintf(" or remove the plugins requesting them: %s", strings.Join(e.Plugins, ", "))
+	}
+
+	return message
+}
+
+func (e *DisallowedIntentsError) Unwrap() error {
+	return e.Err
+}
+
+// checkDisallowedIntents converts a gateway close with code 4014 into a DisallowedIntentsError. Any other error is
+// returned as is.
+func (b *Bot) checkDisallowedIntents(err error) error {
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != closeDisallowedIntents {
+		return err
+	}
+
+	session, _ := b.session()
+	intents := session.Identify.Intents & PrivilegedIntents
+
+	return &DisallowedIntentsError{
+		Intents: intents,
+		Plugins: b.pluginsRequesting(intents),
+		Err:     err,
+	}
+}
+
+// intentMask combines the supplied intents into one.
+func intentMask(intents []discordgo.Intent) discordgo.Intent {
+	var mask discordgo.Intent
+	for _, intent := range intents {
+		mask |= intent
+	}
+
+	return mask
+}
+
+// addIntent adds the intent to every shard.
+func (b *Bot) addIntent(intent discordgo.Intent) {
+	b.shardsLock.Lock()
+	defer b.shardsLock.Unlock()
+
+	for _, session := range b.shards {
+		session.Identify.Intents |= intent
+	}
+}
+
+// dropIntents removes the intents that were requested by a plugin and are no longer requested by any loaded plugin or
+// through AddIntent. The current connection keeps receiving their events until the bot identifies again.
+func (b *Bot) dropIntents(requested discordgo.Intent) {
+	b.shardsLock.RLock()
+	stale := requested &^ b.baseIntents
+	b.shardsLock.RUnlock()
+
+	for _, plugin := range b.loadedPlugins() {
+		stale &^= intentMask(plugin.Intents())
+	}
+
+	b.shardsLock.Lock()
+	defer b.shardsLock.Unlock()
+
+	for _, session := range b.shards {
+		session.Identify.Intents &^= stale
+	}
+}
+
+// missingIntents returns the intents the bot will identify with that the current connection wasn't identified with. A
+// bot that isn't started isn't missing any.
+func (b *Bot) missingIntents() discordgo.Intent {
+	b.shardsLock.RLock()
+	defer b.shardsLock.RUnlock()
+
+	if b.state != StartedState {
+		return 0
+	}
+
+	return b.discordSession.Identify.Intents &^ b.intents
+}
+
+// pluginsRequesting returns the sorted names of the loaded plugins that request any of the supplied intents.
+func (b *Bot) pluginsRequesting(intents discordgo.Intent) []string {
+	var names []string
+	for name, plugin := range b.loadedPlugins() {
+		for _, intent := range plugin.Intents() {
+			if intent&intents != 0 {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// reidentify reconnects to the gateway with a fresh identify, which is the only way to change the intents of a running
+// bot. Closing and re-opening the same sessions would resume them with their original intents instead, so every shard is
+// replaced by a clone when the bot is started again, and the existing handlers are moved over to them.
+func (b *Bot) reidentify() error {
+	reconnect := b.discordSession.ShouldReconnectOnError
+
+	// Keep discordgo from reconnecting the old sessions if one of their goroutines errors while they're being closed.
+	for _, session := range b.shards {
+		session.ShouldReconnectOnError = false
+	}
+
+	if err := b.Stop(); err != nil {
+		return err
+	}
+
+	session := cloneSession(b.discordSession)
+	session.ShouldReconnectOnError = reconnect
+
+	b.shardsLock.Lock()
+	b.discordSession = session
+	b.shardsLock.Unlock()
+
+	return b.Start()
+}
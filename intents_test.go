@@ -0,0 +1,82 @@
+package eris_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+func TestReidentifyKeepsSessionSettings(t *testing.T) {
+	server := eristest.NewServer(t)
+
+	session := server.Session()
+	session.ShouldReconnectOnError = false
+	bot, err := eris.NewBotWithSession(session, eris.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create bot: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = bot.Stop()
+	})
+
+	reconnects := make(chan bool, 1)
+	bot.AddHandler("messages", func(session *discordgo.Session, _ *discordgo.MessageCreate) {
+		reconnects <- session.ShouldReconnectOnError
+	})
+
+	// A privileged intent the bot didn't identify with makes it identify again.
+	plugin := &testPlugin{name: "Content", intents: []discordgo.Intent{discordgo.IntentMessageContent}}
+	if err = bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	intents := server.Intents()
+	if len(intents) != 1 || intents[0]&discordgo.IntentMessageContent == 0 {
+		t.Fatalf("identified intents = %v, want message content included", intents)
+	}
+
+	server.MessageCreate(&discordgo.Message{ChannelID: "400", Content: "hello"})
+
+	select {
+	case reconnect := <-reconnects:
+		if reconnect {
+			t.Error("session reconnects on error after identifying again, want the configured false")
+		}
+	case <-time.After(server.Timeout):
+		t.Fatal("message was never dispatched")
+	}
+}
+
+func TestReidentifyWhileReadingId(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	// Identifying again replaces the session and changes the state, which Id must read consistently meanwhile.
+	done := make(chan struct{})
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_ = bot.Id()
+			}
+		}
+	}()
+
+	plugin := &testPlugin{name: "Content", intents: []discordgo.Intent{discordgo.IntentMessageContent}}
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+	close(done)
+	<-read
+
+	if bot.Id() == "" {
+		t.Error("id of the identified bot is empty")
+	}
+}
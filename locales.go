@@ -0,0 +1,353 @@
+package eris
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocale is the locale messages fall back to when Config.DefaultLocale is unset.
+const DefaultLocale = discordgo.EnglishUS
+
+// Localized can optionally be implemented by a plugin that ships translations. Locales returns a file system holding a
+// file per locale named after it, such as "fr.yaml" or "pt-BR.json". Its keys are relative to the plugin, so they are
+// loaded under the plugin's PluginKey. Translations in the bot's own catalog take precedence over them.
+type Localized interface {
+	Locales() fs.FS
+}
+
+// Catalog holds translated messages keyed by locale and by a dotted key such as "rock_paper_scissors.challenge_sent".
+// Messages loaded into the catalog directly take precedence over those shipped by plugins. It is safe for concurrent
+// use.
+type Catalog struct {
+	fallback discordgo.Locale
+
+	lock     sync.RWMutex
+	messages map[discordgo.Locale]map[string]string
+	defaults map[discordgo.Locale]map[string]string
+}
+
+// NewCatalog returns an empty catalog that falls back to the supplied locale.
+func NewCatalog(fallback discordgo.Locale) *Catalog {
+	return &Catalog{
+		fallback: fallback,
+		messages: make(map[discordgo.Locale]map[string]string),
+		defaults: make(map[discordgo.Locale]map[string]string),
+	}
+}
+
+// Load adds the messages of every YAML or JSON file at the root of fsys to the catalog. Each file is named after its
+// locale, and nested keys are joined with dots, so that
+//
+//	rock_paper_scissors:
+//	  challenge_sent: Défi envoyé !
+//
+// in fr.yaml is the French message of "rock_paper_scissors.challenge_sent".
+func (c *Catalog) Load(fsys fs.FS) error {
+	return c.load(fsys, "", c.messages)
+}
+
+// Add adds messages for the locale, replacing any with the same keys.
+func (c *Catalog) Add(locale discordgo.Locale, messages map[string]string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.add(c.messages, locale, messages)
+}
+
+// Lookup returns the message of the key in the first of the locales that has it. Each locale is followed by the other
+// locales of its language, so that en-GB falls back to en-US, and the catalog's fallback locale is tried last.
+func (c *Catalog) Lookup(key string, locales ...discordgo.Locale) (string, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, locale := range c.candidates(locales) {
+		if message, ok := c.messages[locale][key]; ok {
+			return message, true
+		}
+		if message, ok := c.defaults[locale][key]; ok {
+			return message, true
+		}
+	}
+
+	return "", false
+}
+
+// Localizations returns the message of the key in every locale that has it, as used for the localizations of
+// application commands. It returns nil if no locale does.
+func (c *Catalog) Localizations(key string) map[discordgo.Locale]string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var localizations map[discordgo.Locale]string
+	for _, layer := range []map[discordgo.Locale]map[string]string{c.defaults, c.messages} {
+		for locale, messages := range layer {
+			if message, ok := messages[key]; ok {
+				if localizations == nil {
+					localizations = make(map[discordgo.Locale]string)
+				}
+				localizations[locale] = message
+			}
+		}
+	}
+
+	return localizations
+}
+
+// candidates expands the locales into the order they're tried in. The lock must be held.
+func (c *Catalog) candidates(locales []discordgo.Locale) []discordgo.Locale {
+	var candidates []discordgo.Locale
+	seen := make(map[discordgo.Locale]struct{})
+	try := func(locale discordgo.Locale) {
+		if _, ok := seen[locale]; !ok && locale != "" {
+			seen[locale] = struct{}{}
+			candidates = append(candidates, locale)
+		}
+	}
+
+	for _, locale := range append(append([]discordgo.Locale(nil), locales...), c.fallback) {
+		try(locale)
+
+		var siblings []discordgo.Locale
+		for _, layer := range []map[discordgo.Locale]map[string]string{c.messages, c.defaults} {
+			for other := range layer {
+				if other != locale && localeLanguage(other) == localeLanguage(locale) {
+					siblings = append(siblings, other)
+				}
+			}
+		}
+		sort.Slice(siblings, func(i, j int) bool {
+			return siblings[i] < siblings[j]
+		})
+		for _, sibling := range siblings {
+			try(sibling)
+		}
+	}
+
+	return candidates
+}
+
+// loadDefaults replaces the messages a plugin ships with those in fsys, loaded under the prefix.
+func (c *Catalog) loadDefaults(fsys fs.FS, prefix string) error {
+	c.dropDefaults(prefix)
+
+	return c.load(fsys, prefix, c.defaults)
+}
+
+// dropDefaults removes the messages a plugin ships.
+func (c *Catalog) dropDefaults(prefix string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, messages := range c.defaults {
+		for key := range messages {
+			if strings.HasPrefix(key, prefix) {
+				delete(messages, key)
+			}
+		}
+	}
+}
+
+// load reads every locale file at the root of fsys into the layer, prefixing each key.
+func (c *Catalog) load(fsys fs.FS, prefix string, layer map[discordgo.Locale]map[string]string) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+
+	files := make(map[discordgo.Locale]map[string]string)
+	for _, entry := range entries {
+		extension := path.Ext(entry.Name())
+		if entry.IsDir() || (extension != ".yaml" && extension != ".yml" && extension != ".json") {
+			continue
+		}
+
+		locale := discordgo.Locale(strings.TrimSuffix(entry.Name(), extension))
+		if _, ok := discordgo.Locales[locale]; !ok {
+			return fmt.Errorf("locale file %s isn't named after a Discord locale", entry.Name())
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return err
+		}
+
+		// JSON is valid YAML, so both are decoded the same way.
+		var tree map[string]any
+		if err = yaml.Unmarshal(data, &tree); err != nil {
+			return fmt.Errorf("failed to parse locale file %s: %w", entry.Name(), err)
+		}
+
+		if files[locale] == nil {
+			files[locale] = make(map[string]string)
+		}
+		flattenMessages(files[locale], prefix, tree)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for locale, messages := range files {
+		c.add(layer, locale, messages)
+	}
+
+	return nil
+}
+
+// add adds messages for the locale to the layer. The lock must be held.
+func (c *Catalog) add(layer map[discordgo.Locale]map[string]string, locale discordgo.Locale,
+	messages map[string]string) {
+	if layer[locale] == nil {
+		layer[locale] = make(map[string]string, len(messages))
+	}
+
+	for key, message := range messages {
+		layer[locale][key] = message
+	}
+}
+
+// flattenMessages adds the leaves of a decoded locale file to messages, keyed by their path joined with dots.
+func flattenMessages(messages map[string]string, prefix string, tree map[string]any) {
+	for key, value := range tree {
+		switch value := value.(type) {
+		case map[string]any:
+			flattenMessages(messages, prefix+key+".", value)
+		case string:
+			messages[prefix+key] = value
+		case nil:
+		default:
+			messages[prefix+key] = fmt.Sprint(value)
+		}
+	}
+}
+
+// localeLanguage returns the language part of a locale, e.g. "en" for en-US.
+func localeLanguage(locale discordgo.Locale) string {
+	language, _, _ := strings.Cut(string(locale), "-")
+	return language
+}
+
+// Catalog returns the bot's message catalog, which Config.LocalesPath is loaded into.
+func (b *Bot) Catalog() *Catalog {
+	return b.catalog
+}
+
+// Localize returns the plugin's message for the key in the first of the locales that has it, formatted with args if
+// any are supplied. The key is relative to the plugin, e.g. "challenge_sent". The key itself is returned if no locale
+// has a message for it, so that missing translations stand out.
+func (b *Bot) Localize(plugin string, key string, locales []discordgo.Locale, args ...any) string {
+	message, ok := b.catalog.Lookup(PluginKey(plugin)+"."+key, locales...)
+	if !ok {
+		return key
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+
+	return message
+}
+
+// Locales returns the locales the request's messages should be in: the user's, followed by the guild's.
+func (r *Request) Locales() []discordgo.Locale {
+	locales := []discordgo.Locale{r.Interaction.Locale}
+	if r.Interaction.GuildLocale != nil {
+		locales = append(locales, *r.Interaction.GuildLocale)
+	}
+
+	return locales
+}
+
+// Localize returns the message of the key for the plugin the request was routed to, in the user's locale if there is
+// one, and otherwise in the guild's. See Bot.Localize.
+func (r *Request) Localize(key string, args ...any) string {
+	return r.bot.Localize(r.Plugin, key, r.Locales(), args...)
+}
+
+// loadPluginLocales loads the translations the plugin ships, if any, replacing those it shipped before.
+func (b *Bot) loadPluginLocales(plugin Plugin) error {
+	prefix := PluginKey(plugin.Name()) + "."
+
+	localized, ok := plugin.(Localized)
+	if !ok {
+		b.catalog.dropDefaults(prefix)
+		return nil
+	}
+
+	if err := b.catalog.loadDefaults(localized.Locales(), prefix); err != nil {
+		return fmt.Errorf("failed to load the locales of plugin %q: %w", plugin.Name(), err)
+	}
+
+	return nil
+}
+
+// localizeCommand returns a copy of the plugin's command with the localizations from the catalog filled in, keyed by
+// "<plugin>.commands.<command>". Localizations the plugin set itself are kept.
+func (b *Bot) localizeCommand(plugin string, command *discordgo.ApplicationCommand) *discordgo.ApplicationCommand {
+	key := PluginKey(plugin) + ".commands." + command.Name
+
+	localized := *command
+	if localized.NameLocalizations == nil {
+		if localizations := b.catalog.Localizations(key + ".name"); localizations != nil {
+			localized.NameLocalizations = &localizations
+		}
+	}
+	if localized.DescriptionLocalizations == nil {
+		if localizations := b.catalog.Localizations(key + ".description"); localizations != nil {
+			localized.DescriptionLocalizations = &localizations
+		}
+	}
+	localized.Options = b.localizeOptions(key, command.Options)
+
+	return &localized
+}
+
+// localizeOptions returns copies of the options with their localizations filled in, keyed by "<key>.options.<option>".
+// Subcommand options are descended into, and choices are keyed by "<option key>.choices.<choice>".
+func (b *Bot) localizeOptions(key string,
+	options []*discordgo.ApplicationCommandOption) []*discordgo.ApplicationCommandOption {
+	if options == nil {
+		return nil
+	}
+
+	localized := make([]*discordgo.ApplicationCommandOption, 0, len(options))
+	for _, option := range options {
+		optionKey := key + ".options." + option.Name
+
+		copied := *option
+		if copied.NameLocalizations == nil {
+			copied.NameLocalizations = b.catalog.Localizations(optionKey + ".name")
+		}
+		if copied.DescriptionLocalizations == nil {
+			copied.DescriptionLocalizations = b.catalog.Localizations(optionKey + ".description")
+		}
+		copied.Options = b.localizeOptions(optionKey, option.Options)
+
+		if option.Choices != nil {
+			copied.Choices = make([]*discordgo.ApplicationCommandOptionChoice, 0, len(option.Choices))
+			for _, choice := range option.Choices {
+				copiedChoice := *choice
+				if copiedChoice.NameLocalizations == nil {
+					copiedChoice.NameLocalizations = b.catalog.Localizations(optionKey + ".choices." + choice.Name)
+				}
+				copied.Choices = append(copied.Choices, &copiedChoice)
+			}
+		}
+
+		localized = append(localized, &copied)
+	}
+
+	return localized
+}
+
+// validLocale reports whether the locale is one Discord knows.
+func validLocale(locale string) bool {
+	_, ok := discordgo.Locales[discordgo.Locale(locale)]
+	return ok
+}
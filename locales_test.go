@@ -0,0 +1,128 @@
+package eris_test
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+func TestCatalogLookup(t *testing.T) {
+	catalog := eris.NewCatalog(discordgo.EnglishUS)
+	err := catalog.Load(fstest.MapFS{
+		"en-US.yaml": {Data: []byte("game:\n  won: You won!\n  lost: You lost.\n")},
+		"fr.yaml":    {Data: []byte("game:\n  won: Vous avez gagné !\n")},
+		"pt-BR.json": {Data: []byte(`{"game": {"won": "Você ganhou!"}}`)},
+	})
+	if err != nil {
+		t.Fatalf("failed to load catalog: %v", err)
+	}
+
+	for _, test := range []struct {
+		key     string
+		locales []discordgo.Locale
+		want    string
+	}{
+		{"game.won", []discordgo.Locale{discordgo.French}, "Vous avez gagné !"},
+		{"game.won", []discordgo.Locale{discordgo.PortugueseBR}, "Você ganhou!"},
+		// Locales fall back to others of their language, then to the next locale asked for, then to the catalog's.
+		{"game.won", []discordgo.Locale{discordgo.EnglishGB}, "You won!"},
+		{"game.won", []discordgo.Locale{discordgo.German, discordgo.French}, "Vous avez gagné !"},
+		{"game.lost", []discordgo.Locale{discordgo.French}, "You lost."},
+	} {
+		if message, ok := catalog.Lookup(test.key, test.locales...); !ok || message != test.want {
+			t.Errorf("Lookup(%q, %v) = %q, %t, want %q", test.key, test.locales, message, ok, test.want)
+		}
+	}
+
+	if _, ok := catalog.Lookup("game.tied", discordgo.French); ok {
+		t.Error("Lookup found a message no locale has")
+	}
+}
+
+// localizedPlugin is a test plugin that ships its own translations.
+type localizedPlugin struct {
+	*testPlugin
+	locales fs.FS
+}
+
+func (p *localizedPlugin) Locales() fs.FS {
+	return p.locales
+}
+
+func TestLocalizedPlugin(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	command := chatCommand("greet", "Greets someone")
+	command.Options = []*discordgo.ApplicationCommandOption{
+		{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Who to greet"},
+	}
+	plugin := &localizedPlugin{
+		testPlugin: &testPlugin{
+			name:     "Greeter",
+			commands: map[string]*discordgo.ApplicationCommand{"greet": command},
+			routes: map[string]eris.HandlerFunc{
+				"greet": func(r *eris.Request) error {
+					return r.Respond().Message(r.Localize("greeting", r.Option("name"))).Send()
+				},
+			},
+		},
+		locales: fstest.MapFS{
+			"en-US.yaml": {Data: []byte("greeting: Hello %s\n")},
+			"fr.yaml": {Data: []byte("greeting: Bonjour %s\n" +
+				"commands:\n  greet:\n    name: saluer\n    description: Salue quelqu'un\n" +
+				"    options:\n      name:\n        name: nom\n")},
+		},
+	}
+
+	// The bot's own catalog takes precedence over the plugin's translations.
+	bot.Catalog().Add(discordgo.French, map[string]string{"greeter.commands.greet.description": "Dit bonjour"})
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	var registered *discordgo.ApplicationCommand
+	for _, command := range server.Commands("") {
+		if command.Name == "greet" {
+			registered = command
+		}
+	}
+	switch {
+	case registered == nil:
+		t.Fatal("command wasn't registered")
+	case registered.NameLocalizations == nil || (*registered.NameLocalizations)[discordgo.French] != "saluer":
+		t.Errorf("name localizations = %v, want saluer in French", registered.NameLocalizations)
+	case registered.DescriptionLocalizations == nil ||
+		(*registered.DescriptionLocalizations)[discordgo.French] != "Dit bonjour":
+		t.Errorf("description localizations = %v, want the bot's own in French", registered.DescriptionLocalizations)
+	case registered.Options[0].NameLocalizations[discordgo.French] != "nom":
+		t.Errorf("option name localizations = %v, want nom in French", registered.Options[0].NameLocalizations)
+	}
+
+	for _, test := range []struct {
+		locale, guildLocale discordgo.Locale
+		want                string
+	}{
+		{discordgo.French, "", "Bonjour Ana"},
+		// A user locale without a translation falls back to the guild's.
+		{discordgo.German, discordgo.French, "Bonjour Ana"},
+		{discordgo.German, "", "Hello Ana"},
+	} {
+		interaction := eristest.SlashCommand("500", "greet",
+			eristest.Option("name", discordgo.ApplicationCommandOptionString, "Ana"))
+		interaction.Locale = test.locale
+		if test.guildLocale != "" {
+			interaction = eristest.InGuild(interaction, "1", "10")
+			interaction.GuildLocale = &test.guildLocale
+		}
+
+		interaction = server.InteractionCreate(interaction)
+		if response := server.WaitForResponse(interaction.ID); response.Data.Content != test.want {
+			t.Errorf("response in %s = %q, want %q", test.locale, response.Data.Content, test.want)
+		}
+	}
+}
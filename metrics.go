@@ -0,0 +1,197 @@
+package eris
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metrics holds the Prometheus collectors of a bot. Each bot has its own registry, so that several bots can run in the
+// same process.
+type metrics struct {
+	registry     *prometheus.Registry
+	interactions *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	errors       *prometheus.CounterVec
+	panics       *prometheus.CounterVec
+	rateLimits   prometheus.Counter
+}
+
+func newMetrics(b *Bot) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		interactions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "eris_interactions_total",
+			Help: "Interactions dispatched to a route, by plugin and command path or component pattern.",
+		}, []string{"plugin", "command"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "eris_handler_duration_seconds",
+			Help:    "Time spent in route handlers, including middleware.",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"plugin", "command"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "eris_handler_errors_total",
+			Help: "Route handlers that returned an error.",
+		}, []string{"plugin", "command"}),
+		panics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "eris_handler_panics_total",
+			Help: "Handlers that panicked, by plugin. Handlers added through AddHandler are counted under an empty plugin.",
+		}, []string{"plugin"}),
+		rateLimits: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "eris_rest_rate_limits_total",
+			Help: "REST requests that hit a Discord rate limit.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		m.interactions,
+		m.duration,
+		m.errors,
+		m.panics,
+		m.rateLimits,
+		shardCollector{bot: b},
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+var (
+	shardUpDesc = prometheus.NewDesc("eris_shard_up",
+		"Whether the shard is connected to the gateway.", []string{"shard"}, nil)
+	heartbeatLatencyDesc = prometheus.NewDesc("eris_gateway_heartbeat_latency_seconds",
+		"Time between the last heartbeat sent to the gateway and its acknowledgement.", []string{"shard"}, nil)
+	guildsDesc = prometheus.NewDesc("eris_shard_guilds",
+		"Guilds the shard has received.", []string{"shard"}, nil)
+)
+
+// shardCollector reports the status of every shard at the time of the scrape.
+type shardCollector struct {
+	bot *Bot
+}
+
+func (c shardCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- shardUpDesc
+	descs <- heartbeatLatencyDesc
+	descs <- guildsDesc
+}
+
+func (c shardCollector) Collect(metrics chan<- prometheus.Metric) {
+	for _, shard := range c.bot.State().Shards {
+		id := strconv.Itoa(shard.Id)
+
+		up := 0.0
+		if shard.State == StartedState {
+			up = 1
+		}
+
+		metrics <- prometheus.MustNewConstMetric(shardUpDesc, prometheus.GaugeValue, up, id)
+		metrics <- prometheus.MustNewConstMetric(heartbeatLatencyDesc, prometheus.GaugeValue, shard.Latency.Seconds(), id)
+		metrics <- prometheus.MustNewConstMetric(guildsDesc, prometheus.GaugeValue, float64(shard.Guilds), id)
+	}
+}
+
+// countRateLimit is registered on every shard to count the rate limits discordgo runs into.
+func (b *Bot) countRateLimit(_ *discordgo.Session, _ *discordgo.RateLimit) {
+	b.metrics.rateLimits.Inc()
+}
+
+// Registry returns the Prometheus registry the bot's metrics are registered in. Plugins can register their own
+// collectors in it.
+func (b *Bot) Registry() *prometheus.Registry {
+	return b.metrics.registry
+}
+
+// HTTPHandler returns a handler serving the bot's Prometheus metrics on /metrics and its health on /healthz. It is
+// what Config.MetricsAddr serves, and can be mounted on an existing server instead.
+func (b *Bot) HTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(b.metrics.registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/healthz", b.serveHealth)
+
+	return mux
+}
+
+// Health is the body of a /healthz response.
+type Health struct {
+	State  string        `json:"state"`
+	Ready  bool          `json:"ready"`
+	Shards []ShardHealth `json:"shards"`
+}
+
+// ShardHealth is the health of a single shard in a /healthz response.
+type ShardHealth struct {
+	Id      int     `json:"id"`
+	State   string  `json:"state"`
+	Latency float64 `json:"heartbeat_latency_seconds"`
+	Guilds  int     `json:"guilds"`
+}
+
+// Ready returns whether the bot is started and every one of its shards is connected.
+func (s BotStatus) Ready() bool {
+	if s.State != StartedState {
+		return false
+	}
+
+	for _, shard := range s.Shards {
+		if shard.State != StartedState {
+			return false
+		}
+	}
+
+	return true
+}
+
+// serveHealth answers with 200 if the bot is ready and 503 otherwise, along with the status of every shard.
+func (b *Bot) serveHealth(w http.ResponseWriter, _ *http.Request) {
+	status := b.State()
+
+	health := Health{
+		State:  status.State.String(),
+		Ready:  status.Ready(),
+		Shards: make([]ShardHealth, 0, len(status.Shards)),
+	}
+	for _, shard := range status.Shards {
+		health.Shards = append(health.Shards, ShardHealth{
+			Id:      shard.Id,
+			State:   shard.State.String(),
+			Latency: shard.Latency.Seconds(),
+			Guilds:  shard.Guilds,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !health.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(health)
+}
+
+// serveHTTP starts serving HTTPHandler on addr. The address is bound before returning, so that a port that's already
+// in use is reported right away.
+func (b *Bot) serveHTTP(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	b.httpServer = &http.Server{Handler: b.HTTPHandler()}
+	go func() {
+		if err := b.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			b.Logger.Error("metrics server stopped", slog.String("error", err.Error()))
+		}
+	}()
+
+	b.Logger.Info("serving metrics and health", slog.String("addr", listener.Addr().String()))
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package eris_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+func TestMetrics(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	plugin := &testPlugin{
+		name: "Echo",
+		commands: map[string]*discordgo.ApplicationCommand{
+			"echo": chatCommand("echo", "Echoes"),
+			"fail": chatCommand("fail", "Fails"),
+		},
+		routes: map[string]eris.HandlerFunc{
+			"echo": func(r *eris.Request) error {
+				return r.Respond().Message("echo").Send()
+			},
+			"fail": func(r *eris.Request) error {
+				return errors.New("no database")
+			},
+		},
+	}
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	for _, name := range []string{"echo", "echo", "fail"} {
+		interaction := server.InteractionCreate(eristest.SlashCommand("500", name))
+		server.WaitForResponse(interaction.ID)
+	}
+
+	recorder := httptest.NewRecorder()
+	bot.HTTPHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, line := range []string{
+		`eris_interactions_total{command="echo",plugin="Echo"} 2`,
+		`eris_interactions_total{command="fail",plugin="Echo"} 1`,
+		`eris_handler_errors_total{command="fail",plugin="Echo"} 1`,
+		`eris_handler_duration_seconds_count{command="echo",plugin="Echo"} 2`,
+	} {
+		if !strings.Contains(recorder.Body.String(), line) {
+			t.Errorf("metrics don't contain %s", line)
+		}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	health := func() (int, eris.Health) {
+		recorder := httptest.NewRecorder()
+		bot.HTTPHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		var health eris.Health
+		if err := json.NewDecoder(recorder.Body).Decode(&health); err != nil {
+			t.Fatalf("failed to decode health: %v", err)
+		}
+		return recorder.Code, health
+	}
+
+	if code, health := health(); code != http.StatusServiceUnavailable || health.Ready {
+		t.Errorf("health of a bot that isn't started = %d %+v, want it unavailable", code, health)
+	}
+
+	if err := bot.Start(); err != nil {
+		t.Fatalf("failed to start bot: %v", err)
+	}
+
+	// The shard may still be waiting for its first heartbeat, but the answer has to agree with the body either way.
+	code, started := health()
+	if started.State != "started" || len(started.Shards) != 1 {
+		t.Errorf("health of a started bot = %+v, want it started with one shard", started)
+	}
+	if (code == http.StatusOK) != started.Ready {
+		t.Errorf("health answered %d while ready is %t", code, started.Ready)
+	}
+
+	if err := bot.Stop(); err != nil {
+		t.Fatalf("failed to stop bot: %v", err)
+	}
+	if code, health := health(); code != http.StatusServiceUnavailable || health.State != "stopped" {
+		t.Errorf("health of a stopped bot = %d %+v, want it unavailable and stopped", code, health)
+	}
+}
@@ -0,0 +1,110 @@
+package eris
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Middleware wraps a HandlerFunc to run code before and after it, or to stop the request from reaching it at all.
+//
+// Middleware can be applied at three levels. For every request the bot's global middleware (Bot.Use) runs first, then
+// the middleware of the plugin that owns the route (MiddlewareProvider), and finally any middleware chained onto the
+// route itself (Chain). Within a level, middleware runs in the order it was supplied.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// MiddlewareProvider can optionally be implemented by a plugin to wrap all of its command and component routes.
+type MiddlewareProvider interface {
+	Middleware() []Middleware
+}
+
+// Chain wraps handler in middleware, with the first middleware supplied being the outermost. It can be used to apply
+// middleware to a single route:
+//
+//	routes["config set"] = eris.Chain(p.set, eris.Cooldown(5*time.Second))
+func Chain(handler HandlerFunc, middleware ...Middleware) HandlerFunc {
+	for index := len(middleware) - 1; index >= 0; index-- {
+		handler = middleware[index](handler)
+	}
+
+	return handler
+}
+
+// Use adds global middleware that wraps every routed request, including those of plugins that are already loaded.
+func (b *Bot) Use(middleware ...Middleware) {
+	b.routesLock.Lock()
+	defer b.routesLock.Unlock()
+
+	b.middleware = append(b.middleware, middleware...)
+}
+
+// pluginMiddleware returns the middleware provided by the plugin, if any.
+func pluginMiddleware(plugin Plugin) []Middleware {
+	if provider, ok := plugin.(MiddlewareProvider); ok {
+		return provider.Middleware()
+	}
+
+	return nil
+}
+
+// LogRequests logs every request at debug level along with who made it.
+func LogRequests(logger *slog.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(r *Request) error {
+			logger.Debug("user invoked interaction",
+				slog.String("plugin", r.Plugin),
+				slog.String("path", r.Path),
+				slog.String("user_id", r.UserId()),
+				slog.String("guild_id", r.Interaction.GuildID),
+			)
+
+			return next(r)
+		}
+	}
+}
+
+// logRequests is the default global middleware, which logs requests with the bot's current logger.
+func (b *Bot) logRequests(next HandlerFunc) HandlerFunc {
+	return func(r *Request) error {
+		return LogRequests(b.Logger)(next)(r)
+	}
+}
+
+// Cooldown limits each user to one request per duration for every route it wraps. Requests made during the cooldown
+// are answered with an ephemeral message and don't reach the handler.
+func Cooldown(duration time.Duration) Middleware {
+	var (
+		lock     sync.Mutex
+		lastUsed = make(map[string]time.Time)
+	)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(r *Request) error {
+			key := r.Plugin + "/" + r.Path + "/" + r.UserId()
+			now := time.Now()
+
+			lock.Lock()
+			if last, ok := lastUsed[key]; ok && now.Sub(last) < duration {
+				lock.Unlock()
+
+				remaining := (duration - now.Sub(last)).Round(time.Second)
+				return r.Respond().Ephemeral().
+					Message(fmt.Sprintf("Slow down! Try again in %s.", max(remaining, time.Second))).Send()
+			}
+			lastUsed[key] = now
+
+			// Drop expired entries every now and then so the map doesn't grow forever.
+			if len(lastUsed) > 1024 {
+				for k, last := range lastUsed {
+					if now.Sub(last) >= duration {
+						delete(lastUsed, k)
+					}
+				}
+			}
+			lock.Unlock()
+
+			return next(r)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package eris_test
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+// middlewarePlugin is a test plugin that also wraps its routes in middleware.
+type middlewarePlugin struct {
+	*testPlugin
+	middleware []eris.Middleware
+}
+
+func (p *middlewarePlugin) Middleware() []eris.Middleware {
+	return p.middleware
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	var (
+		lock  sync.Mutex
+		calls []string
+	)
+	record := func(name string) eris.Middleware {
+		return func(next eris.HandlerFunc) eris.HandlerFunc {
+			return func(r *eris.Request) error {
+				lock.Lock()
+				calls = append(calls, name)
+				lock.Unlock()
+				return next(r)
+			}
+		}
+	}
+
+	bot.Use(record("global 1"), record("global 2"))
+	plugin := &middlewarePlugin{
+		testPlugin: &testPlugin{
+			name:     "Echo",
+			commands: map[string]*discordgo.ApplicationCommand{"echo": chatCommand("echo", "Echoes")},
+			routes: map[string]eris.HandlerFunc{
+				"echo": eris.Chain(func(r *eris.Request) error {
+					return r.Respond().Message("echo").Send()
+				}, record("route")),
+			},
+		},
+		middleware: []eris.Middleware{record("plugin")},
+	}
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	interaction := server.InteractionCreate(eristest.SlashCommand("500", "echo"))
+	server.WaitForResponse(interaction.ID)
+
+	lock.Lock()
+	defer lock.Unlock()
+	if want := []string{"global 1", "global 2", "plugin", "route"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("middleware ran in order %v, want %v", calls, want)
+	}
+}
+
+func TestCooldown(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	plugin := &testPlugin{
+		name:     "Echo",
+		commands: map[string]*discordgo.ApplicationCommand{"echo": chatCommand("echo", "Echoes")},
+		routes: map[string]eris.HandlerFunc{
+			"echo": eris.Chain(func(r *eris.Request) error {
+				return r.Respond().Message("echo").Send()
+			}, eris.Cooldown(time.Hour)),
+		},
+	}
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	interaction := server.InteractionCreate(eristest.SlashCommand("500", "echo"))
+	if response := server.WaitForResponse(interaction.ID); response.Data.Content != "echo" {
+		t.Fatalf("first response = %q, want echo", response.Data.Content)
+	}
+
+	interaction = server.InteractionCreate(eristest.SlashCommand("500", "echo"))
+	if response := server.WaitForResponse(interaction.ID); !strings.HasPrefix(response.Data.Content, "Slow down!") {
+		t.Errorf("response during the cooldown = %q, want it to be refused", response.Data.Content)
+	}
+
+	// The cooldown is per user.
+	interaction = server.InteractionCreate(eristest.SlashCommand("501", "echo"))
+	if response := server.WaitForResponse(interaction.ID); response.Data.Content != "echo" {
+		t.Errorf("response to another user = %q, want echo", response.Data.Content)
+	}
+}
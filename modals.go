@@ -0,0 +1,91 @@
+package eris
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris/utils"
+)
+
+// ModalValues returns the submitted values of the modal's text inputs, keyed by their CustomID. It returns nil if the
+// request isn't a modal submit.
+func (r *Request) ModalValues() map[string]string {
+	if r.Interaction.Type != discordgo.InteractionModalSubmit {
+		return nil
+	}
+
+	return utils.ModalValues(r.Interaction.ModalSubmitData())
+}
+
+// ModalValue returns the submitted value of the modal's text input with the CustomID, or an empty string if there is
+// no such input.
+func (r *Request) ModalValue(customId string) string {
+	return r.ModalValues()[customId]
+}
+
+// DecodeModal fills the struct pointed to by v from the text inputs of the submitted modal. See DecodeModalValues.
+func (r *Request) DecodeModal(v any) error {
+	if r.Interaction.Type != discordgo.InteractionModalSubmit {
+		return fmt.Errorf("interaction of type %s isn't a modal submit", r.Interaction.Type)
+	}
+
+	return DecodeModalValues(r.Interaction.ModalSubmitData(), v)
+}
+
+// DecodeModalValues fills the struct pointed to by v from the text inputs of a submitted modal. Fields are matched to
+// inputs by the CustomID in their input tag, which defaults to the field name in snake case, and "-" skips the field:
+//
+//	type challengeModal struct {
+//		Message string `input:"message"`
+//		Rounds  *int   `input:"rounds"`
+//	}
+//
+// Strings are set to the text as entered, while integers, floats and bools are parsed from it. Inputs left empty leave
+// their fields untouched, so pointer fields stay nil.
+func DecodeModalValues(data discordgo.ModalSubmitInteractionData, v any) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode target must be a pointer to a struct, got %T", v)
+	}
+
+	values := utils.ModalValues(data)
+
+	structValue := value.Elem()
+	structType := structValue.Type()
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+		if !field.IsExported() {
+			continue
+		}
+
+		customId, ok := field.Tag.Lookup("input")
+		if customId == "-" {
+			continue
+		}
+		if !ok || customId == "" {
+			customId = snakeCase(field.Name)
+		}
+
+		input := values[customId]
+		if input == "" {
+			continue
+		}
+
+		target := structValue.Field(index)
+		if target.Kind() == reflect.Pointer {
+			pointer := reflect.New(target.Type().Elem())
+			if err := setScalar(pointer.Elem(), input); err != nil {
+				return fmt.Errorf("input %q: %w", customId, err)
+			}
+			target.Set(pointer)
+			continue
+		}
+
+		if err := setScalar(target, input); err != nil {
+			return fmt.Errorf("input %q: %w", customId, err)
+		}
+	}
+
+	return nil
+}
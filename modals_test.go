@@ -0,0 +1,39 @@
+package eris_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+	"github.com/olympus-go/eris/utils"
+)
+
+func TestModalTooManyInputs(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	plugin := &testPlugin{name: "Survey", components: map[string]eris.HandlerFunc{
+		"survey_{id}": func(r *eris.Request) error {
+			modal := utils.Modal("survey_answers", "Survey")
+			for i := 0; i < utils.MaxModalInputs+1; i++ {
+				modal.Inputs(utils.TextInput(fmt.Sprintf("question_%d", i), "Question"))
+			}
+			return r.Respond().Modal(modal).Send()
+		},
+	}}
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	// The modal isn't sent without its last input, so the handler fails and the user is told so instead.
+	interaction := server.InteractionCreate(eristest.Component("500", "survey_1"))
+	response := server.WaitForResponse(interaction.ID)
+	if response.Type == discordgo.InteractionResponseModal {
+		t.Fatalf("modal was sent with %d inputs", len(response.Data.Components))
+	}
+	if response.Data.Content != "Something went wrong." {
+		t.Errorf("response = %q, want the handler's failure", response.Data.Content)
+	}
+}
@@ -0,0 +1,540 @@
+package eris
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var (
+	userType       = reflect.TypeOf((*discordgo.User)(nil))
+	memberType     = reflect.TypeOf((*discordgo.Member)(nil))
+	channelType    = reflect.TypeOf((*discordgo.Channel)(nil))
+	roleType       = reflect.TypeOf((*discordgo.Role)(nil))
+	attachmentType = reflect.TypeOf((*discordgo.MessageAttachment)(nil))
+)
+
+// CommandFromStruct returns a slash command whose options are generated from the fields of options, a struct or a
+// pointer to one. Decode fills such a struct back in from an interaction. Fields are declared with tags:
+//
+//	option:"name"              names the option. It defaults to the field name in snake case, and "-" skips the field.
+//	description:"..."          describes the option. Discord requires a description.
+//	required:"true"            makes the option required.
+//	min:"1" max:"99"           bound the value of integer and number options, or the length of string options.
+//	choices:"Rock=rock,paper"  restrict the option to the listed choices. A choice without a name is named after its value.
+//	autocomplete:"true"        has Discord ask the bot for suggestions while the option is typed.
+//
+// The type of an option follows from the type of its field: strings, integers, floats and bools, or *discordgo.User,
+// *discordgo.Member, *discordgo.Channel, *discordgo.Role and *discordgo.MessageAttachment. Fields that point to a
+// string, integer, float or bool are left nil when the option isn't supplied.
+//
+// A field tagged subcommand:"name" along with a description declares a subcommand, whose options are the fields of the
+// struct it holds or points to. If that struct declares subcommands in turn, it is a subcommand group instead.
+func CommandFromStruct(name string, description string, options any) (*discordgo.ApplicationCommand, error) {
+	command := &discordgo.ApplicationCommand{
+		Name:        name,
+		Description: description,
+		Type:        discordgo.ChatApplicationCommand,
+	}
+
+	if options == nil {
+		return command, nil
+	}
+
+	structType := reflect.TypeOf(options)
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("command %q: options must be a struct, got %T", name, options)
+	}
+
+	generated, err := structOptions(structType, 0)
+	if err != nil {
+		return nil, fmt.Errorf("command %q: %w", name, err)
+	}
+	command.Options = generated
+
+	return command, nil
+}
+
+// MustCommandFromStruct is like CommandFromStruct but panics if the struct doesn't declare a valid command. It is meant
+// for Commands methods, where the struct is fixed at compile time.
+func MustCommandFromStruct(name string, description string, options any) *discordgo.ApplicationCommand {
+	command, err := CommandFromStruct(name, description, options)
+	if err != nil {
+		panic(err)
+	}
+
+	return command
+}
+
+// structOptions generates the options declared by the fields of a struct. depth is the number of subcommand levels
+// above it.
+func structOptions(structType reflect.Type, depth int) ([]*discordgo.ApplicationCommandOption, error) {
+	var options []*discordgo.ApplicationCommandOption
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+		if !field.IsExported() {
+			continue
+		}
+
+		var (
+			option *discordgo.ApplicationCommandOption
+			err    error
+		)
+		if _, ok := field.Tag.Lookup("subcommand"); ok {
+			option, err = subcommandOption(field, depth)
+		} else {
+			option, err = fieldOption(field)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if option != nil {
+			options = append(options, option)
+		}
+	}
+
+	var subcommands int
+	for _, option := range options {
+		if isSubcommand(option.Type) {
+			subcommands++
+		}
+	}
+	if subcommands > 0 && subcommands < len(options) {
+		return nil, fmt.Errorf("%s mixes subcommands with options", structType)
+	}
+
+	// Discord requires required options to come before optional ones.
+	sort.SliceStable(options, func(i, j int) bool {
+		return options[i].Required && !options[j].Required
+	})
+
+	return options, nil
+}
+
+// subcommandOption generates a subcommand, or a subcommand group, from a field tagged with subcommand.
+func subcommandOption(field reflect.StructField, depth int) (*discordgo.ApplicationCommandOption, error) {
+	name := field.Tag.Get("subcommand")
+	if name == "" {
+		name = snakeCase(field.Name)
+	}
+
+	structType := field.Type
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("subcommand %q must be a struct, got %s", name, field.Type)
+	}
+
+	option := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        name,
+		Description: field.Tag.Get("description"),
+	}
+	if option.Description == "" {
+		return nil, fmt.Errorf("subcommand %q has no description", name)
+	}
+
+	options, err := structOptions(structType, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	option.Options = options
+
+	if len(options) > 0 && isSubcommand(options[0].Type) {
+		if depth > 0 {
+			return nil, fmt.Errorf("subcommand %q nests subcommands deeper than Discord allows", name)
+		}
+		option.Type = discordgo.ApplicationCommandOptionSubCommandGroup
+	}
+
+	return option, nil
+}
+
+// fieldOption generates an option from a field. It returns nil for fields tagged option:"-".
+func fieldOption(field reflect.StructField) (*discordgo.ApplicationCommandOption, error) {
+	name, ok := field.Tag.Lookup("option")
+	if name == "-" {
+		return nil, nil
+	}
+	if !ok || name == "" {
+		name = snakeCase(field.Name)
+	}
+
+	optionType, ok := fieldOptionType(field.Type)
+	if !ok {
+		return nil, fmt.Errorf("option %q has unsupported type %s", name, field.Type)
+	}
+
+	option := &discordgo.ApplicationCommandOption{
+		Type:        optionType,
+		Name:        name,
+		Description: field.Tag.Get("description"),
+	}
+	if option.Description == "" {
+		return nil, fmt.Errorf("option %q has no description", name)
+	}
+
+	var err error
+	if option.Required, err = boolTag(field, "required"); err != nil {
+		return nil, fmt.Errorf("option %q: %w", name, err)
+	}
+	if option.Autocomplete, err = boolTag(field, "autocomplete"); err != nil {
+		return nil, fmt.Errorf("option %q: %w", name, err)
+	}
+
+	for _, bound := range []string{"min", "max"} {
+		value, ok := field.Tag.Lookup(bound)
+		if !ok {
+			continue
+		}
+
+		limit, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("option %q has an invalid %s %q", name, bound, value)
+		}
+
+		switch {
+		case optionType == discordgo.ApplicationCommandOptionInteger ||
+			optionType == discordgo.ApplicationCommandOptionNumber:
+			if bound == "min" {
+				option.MinValue = &limit
+			} else {
+				option.MaxValue = limit
+			}
+		case optionType == discordgo.ApplicationCommandOptionString:
+			length := int(limit)
+			if bound == "min" {
+				option.MinLength = &length
+			} else {
+				option.MaxLength = length
+			}
+		default:
+			return nil, fmt.Errorf("option %q of type %s can't have a %s", name, optionType, bound)
+		}
+	}
+
+	if choices, ok := field.Tag.Lookup("choices"); ok {
+		if option.Autocomplete {
+			return nil, fmt.Errorf("option %q can't have both choices and autocomplete", name)
+		}
+		if option.Choices, err = parseChoices(optionType, choices); err != nil {
+			return nil, fmt.Errorf("option %q: %w", name, err)
+		}
+	}
+
+	return option, nil
+}
+
+// fieldOptionType returns the option type a field of the supplied type declares.
+func fieldOptionType(fieldType reflect.Type) (discordgo.ApplicationCommandOptionType, bool) {
+	switch fieldType {
+	case userType, memberType:
+		return discordgo.ApplicationCommandOptionUser, true
+	case channelType:
+		return discordgo.ApplicationCommandOptionChannel, true
+	case roleType:
+		return discordgo.ApplicationCommandOptionRole, true
+	case attachmentType:
+		return discordgo.ApplicationCommandOptionAttachment, true
+	}
+
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String:
+		return discordgo.ApplicationCommandOptionString, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return discordgo.ApplicationCommandOptionInteger, true
+	case reflect.Float32, reflect.Float64:
+		return discordgo.ApplicationCommandOptionNumber, true
+	case reflect.Bool:
+		return discordgo.ApplicationCommandOptionBoolean, true
+	default:
+		return 0, false
+	}
+}
+
+// parseChoices parses a comma separated list of choices, each either "name=value" or just a value.
+func parseChoices(optionType discordgo.ApplicationCommandOptionType,
+	list string) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, entry := range strings.Split(list, ",") {
+		name, raw, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			raw = name
+		}
+
+		var value any
+		switch optionType {
+		case discordgo.ApplicationCommandOptionString:
+			value = raw
+		case discordgo.ApplicationCommandOptionInteger:
+			integer, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("choice %q is not an integer", raw)
+			}
+			value = integer
+		case discordgo.ApplicationCommandOptionNumber:
+			number, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("choice %q is not a number", raw)
+			}
+			value = number
+		default:
+			return nil, fmt.Errorf("options of type %s can't have choices", optionType)
+		}
+
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: value})
+	}
+
+	return choices, nil
+}
+
+// boolTag parses a tag holding a bool, which is false if the tag is missing.
+func boolTag(field reflect.StructField, key string) (bool, error) {
+	value, ok := field.Tag.Lookup(key)
+	if !ok {
+		return false, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q", key, value)
+	}
+
+	return parsed, nil
+}
+
+func isSubcommand(optionType discordgo.ApplicationCommandOptionType) bool {
+	return optionType == discordgo.ApplicationCommandOptionSubCommand ||
+		optionType == discordgo.ApplicationCommandOptionSubCommandGroup
+}
+
+// snakeCase converts a field name such as "MaxGuesses" into an option name such as "max_guesses".
+func snakeCase(name string) string {
+	var builder strings.Builder
+	runes := []rune(name)
+	for index, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word at an upper case letter, unless it continues an acronym such as the "ID" of "UserID".
+			if index > 0 && (unicode.IsLower(runes[index-1]) ||
+				(index+1 < len(runes) && unicode.IsLower(runes[index+1]) && unicode.IsUpper(runes[index-1]))) {
+				builder.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
+
+// Decode fills the struct pointed to by v, declared as described by CommandFromStruct, from the options of the
+// request's command. Options that weren't supplied leave their fields untouched. Subcommand fields are only filled
+// along the path that was invoked, and the other subcommand fields are left nil. A struct without subcommand fields is
+// filled from the options of the invoked subcommand, so that a route for a single subcommand can decode just its
+// options.
+func (r *Request) Decode(v any) error {
+	return DecodeOptions(r.Interaction.Interaction, v)
+}
+
+// DecodeOptions is like Request.Decode for an application command or autocomplete interaction that wasn't routed.
+func DecodeOptions(interaction *discordgo.Interaction, v any) error {
+	if interaction.Type != discordgo.InteractionApplicationCommand &&
+		interaction.Type != discordgo.InteractionApplicationCommandAutocomplete {
+		return fmt.Errorf("interaction of type %s has no command options", interaction.Type)
+	}
+
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode target must be a pointer to a struct, got %T", v)
+	}
+
+	data := interaction.ApplicationCommandData()
+
+	return decodeStruct(value.Elem(), data.Options, data.Resolved)
+}
+
+// decodeStruct fills the fields of a struct from the supplied options.
+func decodeStruct(value reflect.Value, options []*discordgo.ApplicationCommandInteractionDataOption,
+	resolved *discordgo.ApplicationCommandInteractionDataResolved) error {
+	structType := value.Type()
+
+	hasSubcommands := false
+	for index := 0; index < structType.NumField(); index++ {
+		if _, ok := structType.Field(index).Tag.Lookup("subcommand"); ok {
+			hasSubcommands = true
+			break
+		}
+	}
+
+	// A struct of plain options is filled from the invoked subcommand.
+	if !hasSubcommands {
+		for len(options) > 0 && isSubcommand(options[0].Type) {
+			options = options[0].Options
+		}
+	}
+
+	byName := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, option := range options {
+		byName[option.Name] = option
+	}
+
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+		if !field.IsExported() {
+			continue
+		}
+
+		if name, ok := field.Tag.Lookup("subcommand"); ok {
+			if name == "" {
+				name = snakeCase(field.Name)
+			}
+
+			option, ok := byName[name]
+			if !ok || !isSubcommand(option.Type) {
+				continue
+			}
+
+			target := value.Field(index)
+			if target.Kind() == reflect.Pointer {
+				target.Set(reflect.New(field.Type.Elem()))
+				target = target.Elem()
+			}
+			if err := decodeStruct(target, option.Options, resolved); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, ok := field.Tag.Lookup("option")
+		if name == "-" {
+			continue
+		}
+		if !ok || name == "" {
+			name = snakeCase(field.Name)
+		}
+
+		option, ok := byName[name]
+		if !ok || option.Value == nil {
+			continue
+		}
+
+		if err := decodeOption(value.Field(index), option, resolved); err != nil {
+			return fmt.Errorf("option %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// decodeOption sets a field to the value of an option. Values are converted rather than asserted, since the focused
+// option of an autocomplete interaction holds whatever the user typed so far.
+func decodeOption(field reflect.Value, option *discordgo.ApplicationCommandInteractionDataOption,
+	resolved *discordgo.ApplicationCommandInteractionDataResolved) error {
+	if resolved == nil {
+		resolved = &discordgo.ApplicationCommandInteractionDataResolved{}
+	}
+	id := fmt.Sprint(option.Value)
+
+	switch field.Type() {
+	case userType:
+		user, ok := resolved.Users[id]
+		if !ok {
+			user = &discordgo.User{ID: id}
+		}
+		field.Set(reflect.ValueOf(user))
+		return nil
+	case memberType:
+		member, ok := resolved.Members[id]
+		if !ok {
+			member = &discordgo.Member{}
+		}
+		if member.User == nil {
+			member.User = resolved.Users[id]
+			if member.User == nil {
+				member.User = &discordgo.User{ID: id}
+			}
+		}
+		field.Set(reflect.ValueOf(member))
+		return nil
+	case channelType:
+		channel, ok := resolved.Channels[id]
+		if !ok {
+			channel = &discordgo.Channel{ID: id}
+		}
+		field.Set(reflect.ValueOf(channel))
+		return nil
+	case roleType:
+		role, ok := resolved.Roles[id]
+		if !ok {
+			role = &discordgo.Role{ID: id}
+		}
+		field.Set(reflect.ValueOf(role))
+		return nil
+	case attachmentType:
+		attachment, ok := resolved.Attachments[id]
+		if !ok {
+			attachment = &discordgo.MessageAttachment{ID: id}
+		}
+		field.Set(reflect.ValueOf(attachment))
+		return nil
+	}
+
+	if field.Kind() == reflect.Pointer {
+		target := reflect.New(field.Type().Elem())
+		if err := setScalar(target.Elem(), option.Value); err != nil {
+			return err
+		}
+		field.Set(target)
+		return nil
+	}
+
+	return setScalar(field, option.Value)
+}
+
+// setScalar sets a string, integer, float or bool to an option value.
+func setScalar(target reflect.Value, value any) error {
+	text := fmt.Sprint(value)
+
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil || number != float64(int64(number)) {
+			return fmt.Errorf("%q is not an integer", text)
+		}
+		if target.OverflowInt(int64(number)) {
+			return fmt.Errorf("%s overflows %s", text, target.Type())
+		}
+		target.SetInt(int64(number))
+	case reflect.Float32, reflect.Float64:
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", text)
+		}
+		target.SetFloat(number)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("%q is not a bool", text)
+		}
+		target.SetBool(parsed)
+	default:
+		return fmt.Errorf("unsupported type %s", target.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package eris_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+type startOptions struct {
+	Questions int    `description:"Number of questions" required:"true" min:"1" max:"99"`
+	Theme     string `description:"Theme" choices:"Characters=c,Animals=a"`
+	ChildMode *bool  `description:"Filters adult content"`
+	Internal  string `option:"-"`
+}
+
+type gameOptions struct {
+	Start *startOptions `subcommand:"start" description:"Starts a game"`
+	Stop  *struct{}     `subcommand:"stop" description:"Stops the game"`
+}
+
+func TestCommandFromStruct(t *testing.T) {
+	command, err := eris.CommandFromStruct("game", "Plays a game", gameOptions{})
+	if err != nil {
+		t.Fatalf("failed to generate command: %v", err)
+	}
+
+	if len(command.Options) != 2 || command.Options[0].Name != "start" || command.Options[1].Name != "stop" {
+		t.Fatalf("options = %+v, want the start and stop subcommands", command.Options)
+	}
+	start := command.Options[0]
+	if start.Type != discordgo.ApplicationCommandOptionSubCommand || len(start.Options) != 3 {
+		t.Fatalf("start = %+v, want a subcommand with 3 options", start)
+	}
+
+	questions, theme, childMode := start.Options[0], start.Options[1], start.Options[2]
+	if questions.Name != "questions" || questions.Type != discordgo.ApplicationCommandOptionInteger ||
+		!questions.Required || questions.MinValue == nil || *questions.MinValue != 1 || questions.MaxValue != 99 {
+		t.Errorf("questions = %+v, want a required integer between 1 and 99", questions)
+	}
+	want := []*discordgo.ApplicationCommandOptionChoice{{Name: "Characters", Value: "c"}, {Name: "Animals", Value: "a"}}
+	if theme.Type != discordgo.ApplicationCommandOptionString || !reflect.DeepEqual(theme.Choices, want) {
+		t.Errorf("theme = %+v, want a string with the choices %+v", theme, want)
+	}
+	if childMode.Name != "child_mode" || childMode.Type != discordgo.ApplicationCommandOptionBoolean ||
+		childMode.Required {
+		t.Errorf("child_mode = %+v, want an optional bool", childMode)
+	}
+
+	type invalid struct {
+		Rounds int `min:"1"`
+	}
+	if _, err := eris.CommandFromStruct("game", "Plays a game", invalid{}); err == nil {
+		t.Error("generating an option without a description succeeded")
+	}
+}
+
+func TestDecodeOptions(t *testing.T) {
+	interaction := eristest.SlashCommand("500", "game", eristest.SubCommand("start",
+		eristest.Option("questions", discordgo.ApplicationCommandOptionInteger, float64(20)),
+		eristest.Option("theme", discordgo.ApplicationCommandOptionString, "a")))
+
+	options := gameOptions{Stop: &struct{}{}}
+	if err := eris.DecodeOptions(interaction, &options); err != nil {
+		t.Fatalf("failed to decode options: %v", err)
+	}
+	if options.Stop == nil {
+		t.Error("subcommand that wasn't invoked was reset")
+	}
+	if options.Start == nil {
+		t.Fatal("invoked subcommand wasn't decoded")
+	}
+	if want := (startOptions{Questions: 20, Theme: "a"}); !reflect.DeepEqual(*options.Start, want) {
+		t.Errorf("start = %+v, want %+v", *options.Start, want)
+	}
+
+	// A route for a single subcommand can decode just its options.
+	var start startOptions
+	if err := eris.DecodeOptions(interaction, &start); err != nil || start.Questions != 20 {
+		t.Errorf("decoding the subcommand's options returned %+v, %v", start, err)
+	}
+
+	wrongType := eristest.SlashCommand("500", "game", eristest.SubCommand("start",
+		eristest.Option("questions", discordgo.ApplicationCommandOptionInteger, "many")))
+	if err := eris.DecodeOptions(wrongType, &options); err == nil {
+		t.Error("decoding text into an integer succeeded")
+	}
+}
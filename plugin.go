@@ -1,11 +1,10 @@
 package eris
 
 import (
+	"context"
 	"fmt"
-	"log/slog"
 
 	"github.com/bwmarrin/discordgo"
-	"github.com/olympus-go/eris/utils"
 )
 
 // Plugin is the baseline interface for extending bot behavior.
@@ -17,9 +16,22 @@ type Plugin interface {
 	Intents() []discordgo.Intent
 }
 
+// Initializer can optionally be implemented by a plugin that needs a reference to the Bot or has to set up resources
+// before it starts handling events. Init is called by AddPlugin before the plugin's routes, handlers and commands are
+// registered, so no interaction reaches the plugin before Init returned. An error aborts the registration, and a plugin
+// that fails to register after Init is closed again.
+type Initializer interface {
+	Init(ctx context.Context, bot *Bot) error
+}
+
+// Closer can optionally be implemented by a plugin that holds resources which need to be released. Close is called
+// when the plugin is removed or reloaded, and when the bot shuts down.
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
 type PluginManager struct {
-	plugins *map[string]Plugin
-	logger  *slog.Logger
+	bot *Bot
 }
 
 func (p PluginManager) Name() string {
@@ -31,27 +43,23 @@ func (p PluginManager) Description() string {
 }
 
 func (p PluginManager) Handlers() map[string]any {
-	handlers := make(map[string]any)
-
-	handlers["plugin_handler"] = func(session *discordgo.Session, i *discordgo.InteractionCreate) {
-		switch i.Type {
-		case discordgo.InteractionApplicationCommand:
-			if i.ApplicationCommandData().Name == "plugins" {
-				message := ""
-
-				for _, plugin := range *p.plugins {
-					message += fmt.Sprintf("%s - %s\n", plugin.Name(), plugin.Description())
-				}
-
-				utils.InteractionResponse(session, i.Interaction).
-					Ephemeral().
-					Message("```" + message + "```").
-					SendWithLog(p.logger)
-			}
+	return nil
+}
+
+func (p PluginManager) Routes() map[string]HandlerFunc {
+	routes := make(map[string]HandlerFunc)
+
+	routes["plugins"] = func(r *Request) error {
+		message := ""
+
+		for _, plugin := range p.bot.loadedPlugins() {
+			message += fmt.Sprintf("%s - %s\n", plugin.Name(), plugin.Description())
 		}
+
+		return r.Respond().Ephemeral().Message("```" + message + "```").Send()
 	}
 
-	return handlers
+	return routes
 }
 
 func (p PluginManager) Commands() map[string]*discordgo.ApplicationCommand {
@@ -0,0 +1,413 @@
+package eris_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+func TestPluginManager(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	if err := bot.AddPlugin(&testPlugin{name: "Echo"}); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	interaction := server.InteractionCreate(eristest.SlashCommand("500", "plugins"))
+	response := server.WaitForResponse(interaction.ID)
+
+	if response.Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+		t.Error("plugin list isn't ephemeral")
+	}
+	for _, line := range []string{"Plugin Info - Lists currently loaded plugins", "Echo - Test plugin Echo"} {
+		if !strings.Contains(response.Data.Content, line) {
+			t.Errorf("plugin list %q doesn't contain %q", response.Data.Content, line)
+		}
+	}
+
+	if err := bot.UnloadPlugin("Echo"); err != nil {
+		t.Fatalf("failed to unload plugin: %v", err)
+	}
+
+	interaction = server.InteractionCreate(eristest.SlashCommand("500", "plugins"))
+	response = server.WaitForResponse(interaction.ID)
+
+	if strings.Contains(response.Data.Content, "Echo") {
+		t.Errorf("plugin list %q still contains the unloaded plugin", response.Data.Content)
+	}
+}
+
+// lifecyclePlugin is a test plugin that also implements Initializer and Closer.
+type lifecyclePlugin struct {
+	*testPlugin
+	init   func(ctx context.Context, bot *eris.Bot) error
+	closed atomic.Bool
+}
+
+func (p *lifecyclePlugin) Init(ctx context.Context, bot *eris.Bot) error {
+	if p.init == nil {
+		return nil
+	}
+
+	return p.init(ctx, bot)
+}
+
+func (p *lifecyclePlugin) Close(_ context.Context) error {
+	p.closed.Store(true)
+	return nil
+}
+
+// logSignal is a log handler that signals every record with the message, so that tests can wait for something that
+// leaves no other trace, such as an interaction being dropped.
+type logSignal struct {
+	message string
+	signal  chan struct{}
+}
+
+func newLogSignal(message string) *logSignal {
+	return &logSignal{message: message, signal: make(chan struct{}, 16)}
+}
+
+func (s *logSignal) Enabled(_ context.Context, _ slog.Level) bool {
+	return true
+}
+
+func (s *logSignal) Handle(_ context.Context, record slog.Record) error {
+	if record.Message == s.message {
+		select {
+		case s.signal <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+func (s *logSignal) WithAttrs(_ []slog.Attr) slog.Handler {
+	return s
+}
+
+func (s *logSignal) WithGroup(_ string) slog.Handler {
+	return s
+}
+
+// wait blocks until the message is logged, reporting whether it was before the timeout.
+func (s *logSignal) wait(timeout time.Duration) bool {
+	select {
+	case <-s.signal:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func TestAddPluginRoutesAfterInit(t *testing.T) {
+	server := eristest.NewServer(t)
+	unrouted := newLogSignal("received unrouted component")
+	bot, err := server.NewBot(eris.Config{}, unrouted)
+	if err != nil {
+		t.Fatalf("failed to create bot: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = bot.Stop()
+	})
+
+	var initialized, early atomic.Bool
+	plugin := &lifecyclePlugin{
+		testPlugin: &testPlugin{
+			name: "Echo",
+			components: map[string]eris.HandlerFunc{
+				"echo_{id}": func(r *eris.Request) error {
+					if !initialized.Load() {
+						early.Store(true)
+					}
+					return r.Respond().Message("echo").Send()
+				},
+			},
+		},
+	}
+	plugin.init = func(_ context.Context, _ *eris.Bot) error {
+		// An interaction arriving while Init runs must not reach the plugin. Unrouted components get no response, so
+		// wait for it to be dropped instead.
+		server.InteractionCreate(eristest.Component("500", "echo_1"))
+		if !unrouted.wait(server.Timeout) {
+			t.Error("component sent during Init was neither dropped nor answered")
+		}
+		initialized.Store(true)
+		return nil
+	}
+
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+	if early.Load() {
+		t.Error("component was dispatched before Init returned")
+	}
+
+	interaction := server.InteractionCreate(eristest.Component("500", "echo_2"))
+	if response := server.WaitForResponse(interaction.ID); response.Data.Content != "echo" {
+		t.Errorf("response = %q, want echo", response.Data.Content)
+	}
+}
+
+func TestAddPluginInitFailure(t *testing.T) {
+	server := eristest.NewServer(t)
+	unrouted := newLogSignal("received unrouted component")
+	bot, err := server.NewBot(eris.Config{}, unrouted)
+	if err != nil {
+		t.Fatalf("failed to create bot: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = bot.Stop()
+	})
+
+	var dispatched atomic.Bool
+	plugin := &lifecyclePlugin{
+		testPlugin: &testPlugin{
+			name: "Echo",
+			components: map[string]eris.HandlerFunc{
+				"echo_{id}": func(r *eris.Request) error {
+					dispatched.Store(true)
+					return r.Respond().Message("echo").Send()
+				},
+			},
+		},
+		init: func(_ context.Context, _ *eris.Bot) error {
+			return errors.New("no database")
+		},
+	}
+
+	if err := bot.AddPlugin(plugin); err == nil {
+		t.Fatal("adding a plugin whose Init fails succeeded")
+	}
+
+	server.InteractionCreate(eristest.Component("500", "echo_1"))
+	if !unrouted.wait(server.Timeout) {
+		t.Fatal("component of a plugin whose Init failed wasn't dropped")
+	}
+	if dispatched.Load() {
+		t.Error("route of a plugin whose Init failed was dispatched")
+	}
+}
+
+func TestAddPluginRouteConflictClosesPlugin(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	handler := func(r *eris.Request) error {
+		return r.Respond().Message("ok").Send()
+	}
+	first := &testPlugin{name: "First", routes: map[string]eris.HandlerFunc{"shared": handler}}
+	if err := bot.AddPlugin(first); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	second := &lifecyclePlugin{
+		testPlugin: &testPlugin{name: "Second", routes: map[string]eris.HandlerFunc{"shared": handler}},
+	}
+	if err := bot.AddPlugin(second); err == nil {
+		t.Fatal("adding a plugin with a conflicting route succeeded")
+	}
+	if !second.closed.Load() {
+		t.Error("initialized plugin wasn't closed after failing to register")
+	}
+}
+
+func TestReloadPluginWhileDispatching(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	newEcho := func() *lifecyclePlugin {
+		return &lifecyclePlugin{
+			testPlugin: &testPlugin{
+				name:     "Echo",
+				commands: map[string]*discordgo.ApplicationCommand{"echo": chatCommand("echo", "Echoes")},
+				routes: map[string]eris.HandlerFunc{
+					"echo": func(r *eris.Request) error {
+						return r.Respond().Message("echo").Send()
+					},
+				},
+			},
+		}
+	}
+	if err := bot.AddPlugin(newEcho()); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	// Listing the plugins reads them while they're swapped, which the race detector catches if it isn't synchronized.
+	// Each interaction is awaited before the next is sent, so that handlers keep running concurrently with the reloads.
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			for _, name := range []string{"plugins", "echo"} {
+				interaction := server.InteractionCreate(eristest.SlashCommand("500", name))
+
+				var response *discordgo.InteractionResponse
+				server.WaitFor(func() bool {
+					response, _ = server.Response(interaction.ID)
+					return response != nil
+				})
+				if response == nil {
+					t.Errorf("/%s wasn't responded to during a reload", name)
+				} else if response.Data.Content == "Unknown command." {
+					t.Errorf("/%s wasn't routed during a reload", name)
+				}
+			}
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		if err := bot.ReloadPlugin("Echo"); err != nil {
+			t.Errorf("failed to reload plugin: %v", err)
+		}
+		if err := bot.ReplacePlugin(newEcho()); err != nil {
+			t.Errorf("failed to replace plugin: %v", err)
+		}
+	}
+
+	close(stop)
+	<-done
+}
+
+func TestReplacePluginFailureKeepsOld(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	handler := func(r *eris.Request) error {
+		return r.Respond().Message("ok").Send()
+	}
+	if err := bot.AddPlugin(&testPlugin{name: "Other", routes: map[string]eris.HandlerFunc{"shared": handler}}); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	at := time.Now().Add(time.Hour)
+	old := newTaskPlugin(func(_ context.Context, bot *eris.Bot) error {
+		return bot.Schedule("Tasks", eris.Job{Key: "reminder", Task: "noop", At: at, Persist: true})
+	})
+	if err := bot.AddPlugin(old); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	// The new instance schedules its own jobs, and only fails to register its routes once it's initialized.
+	replacement := newTaskPlugin(func(_ context.Context, bot *eris.Bot) error {
+		for _, key := range []string{"reminder", "digest"} {
+			job := eris.Job{Key: key, Task: "noop", At: at.Add(time.Hour), Persist: true}
+			if err := bot.Schedule("Tasks", job); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	replacement.routes = map[string]eris.HandlerFunc{"shared": handler}
+	if err := bot.ReplacePlugin(replacement); err == nil {
+		t.Fatal("replacing a plugin with one whose route conflicts succeeded")
+	}
+
+	if !replacement.closed.Load() {
+		t.Error("initialized replacement wasn't closed after failing to register")
+	}
+	if old.closed.Load() {
+		t.Error("old instance was closed although it stays loaded")
+	}
+
+	jobs := bot.Jobs("Tasks")
+	if len(jobs) != 1 || jobs[0].Key != "reminder" || !jobs[0].At.Equal(at) {
+		t.Errorf("jobs = %+v, want only the old instance's reminder", jobs)
+	}
+	if keys := storedJobs(t, bot, "Tasks"); len(keys) != 1 || keys[0] != "jobs/reminder" {
+		t.Errorf("persisted jobs = %v, want only the old instance's reminder", keys)
+	}
+}
+
+func TestReloadPluginInitFailureUnloads(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	var initialized atomic.Bool
+	plugin := &lifecyclePlugin{
+		testPlugin: &testPlugin{
+			name:     "Echo",
+			commands: map[string]*discordgo.ApplicationCommand{"echo": chatCommand("echo", "Echoes")},
+			routes: map[string]eris.HandlerFunc{
+				"echo": func(r *eris.Request) error {
+					return r.Respond().Message("echo").Send()
+				},
+			},
+		},
+	}
+	plugin.init = func(_ context.Context, _ *eris.Bot) error {
+		if initialized.Swap(true) {
+			return errors.New("no database")
+		}
+		return nil
+	}
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	if err := bot.ReloadPlugin("Echo"); err == nil || !strings.Contains(err.Error(), "unloaded") {
+		t.Fatalf("reloading a plugin whose Init fails returned %v, want an error reporting it was unloaded", err)
+	}
+	if !plugin.closed.Load() {
+		t.Error("plugin wasn't closed before it was initialized again")
+	}
+
+	interaction := server.InteractionCreate(eristest.SlashCommand("500", "echo"))
+	if response := server.WaitForResponse(interaction.ID); response.Data.Content != "Unknown command." {
+		t.Errorf("/echo response = %q, want the closed plugin's route to be removed", response.Data.Content)
+	}
+
+	interaction = server.InteractionCreate(eristest.SlashCommand("500", "plugins"))
+	if response := server.WaitForResponse(interaction.ID); strings.Contains(response.Data.Content, "Echo") {
+		t.Errorf("plugin list %q still contains the unloaded plugin", response.Data.Content)
+	}
+
+	if err := bot.AddPlugin(&testPlugin{name: "Echo"}); err != nil {
+		t.Errorf("failed to add the plugin again: %v", err)
+	}
+}
+
+func TestReplacePluginCommandConflictSkipsInit(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	if err := bot.AddPlugin(&testPlugin{name: "Echo"}); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	// Declaring a command of another plugin is caught before the new instance is initialized, as in AddPlugin.
+	var initialized atomic.Bool
+	replacement := &lifecyclePlugin{
+		testPlugin: &testPlugin{
+			name:     "Echo",
+			commands: map[string]*discordgo.ApplicationCommand{"plugins": chatCommand("plugins", "Lists plugins")},
+		},
+		init: func(_ context.Context, _ *eris.Bot) error {
+			initialized.Store(true)
+			return nil
+		},
+	}
+	if err := bot.ReplacePlugin(replacement); err == nil {
+		t.Fatal("replacing a plugin with one declaring another plugin's command succeeded")
+	}
+	if initialized.Load() {
+		t.Error("replacement was initialized although its commands conflict")
+	}
+}
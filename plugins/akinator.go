@@ -1,17 +1,21 @@
 package plugins
 
 import (
+	"context"
 	"fmt"
 	"github.com/bwmarrin/discordgo"
-	"github.com/eolso/threadsafe"
-	"github.com/olympus-go/athena"
+	"github.com/olympus-go/eris"
 	"github.com/olympus-go/eris/utils"
-	"github.com/rs/zerolog"
-	"strconv"
+	"log/slog"
 	"strings"
+	"time"
 	"unicode"
 )
 
+// akiSessionTTL is how long a game is kept around while the user doesn't answer. Interaction tokens are only valid for
+// 15 minutes, so the game's messages can't be updated after that anyway.
+const akiSessionTTL = 15 * time.Minute
+
 const (
 	akiStateNil             = 0
 	akiStateThemeSelection  = 1
@@ -20,14 +24,48 @@ const (
 	akiStateProcessing      = 4
 )
 
-type akinatorGuess struct {
-	name     string
-	imageUrl string
+// AkinatorGuess is a character the backend guesses the user is thinking of.
+type AkinatorGuess struct {
+	Name     string
+	ImageUrl string
+}
+
+// AkinatorSelection is a question of the game along with the answer the user gave.
+type AkinatorSelection struct {
+	Question string
+	Answer   string
+}
+
+// AkinatorClient plays a single game of 21 questions against a backend, such as the one provided by
+// github.com/olympus-go/athena. A client is only used by one game, and only by one handler at a time.
+type AkinatorClient interface {
+	// Themes returns the names of the themes a game can be started in.
+	Themes() []string
+	// NewGame starts the game in the theme with the index into Themes.
+	NewGame(theme int) error
+	// Question returns the current question, and Answers the answers it can be given.
+	Question() string
+	Answers() []string
+	// Answer answers the current question with the index into Answers and moves on to the next question.
+	Answer(answer int) error
+	// Undo takes back the last answer.
+	Undo() error
+	// Step returns the zero based number of the current question.
+	Step() int
+	// Progress returns how confident the backend is in its guesses, from 0 to 100.
+	Progress() float64
+	// History returns the questions answered so far.
+	History() []AkinatorSelection
+	// Guesses returns the characters the backend currently guesses, best first.
+	Guesses() ([]AkinatorGuess, error)
 }
 
+// AkinatorClientFunc creates the client of a new game.
+type AkinatorClientFunc func() (AkinatorClient, error)
+
 type akinatorSession struct {
-	client              *athena.Client
-	themes              []athena.Theme
+	discord             *discordgo.Session
+	client              AkinatorClient
 	interaction         *discordgo.Interaction
 	ownerId             string
 	state               int
@@ -37,19 +75,65 @@ type akinatorSession struct {
 	maxGuesses          int
 	guessCooldown       int
 	guessMessageId      string
-	previousGuesses     []akinatorGuess
+	previousGuesses     []AkinatorGuess
+}
+
+// AkinatorConfig holds the game defaults used when a game is started without the matching options.
+type AkinatorConfig struct {
+	Questions  int     `yaml:"questions"`
+	Confidence float64 `yaml:"confidence"`
+	Guesses    int     `yaml:"guesses"`
+}
+
+func (c *AkinatorConfig) Validate() error {
+	if c.Questions < 1 || c.Questions > 99 {
+		return fmt.Errorf("questions must be between 1 and 99, got %d", c.Questions)
+	}
+	if c.Confidence < 1 || c.Confidence > 99 {
+		return fmt.Errorf("confidence must be between 1 and 99, got %.1f", c.Confidence)
+	}
+	if c.Guesses < 1 || c.Guesses > 5 {
+		return fmt.Errorf("guesses must be between 1 and 5, got %d", c.Guesses)
+	}
+
+	return nil
+}
+
+// akiCommand declares the /21q command.
+type akiCommand struct {
+	Start   *akiStartOptions `subcommand:"start" description:"Starts a new game of 21* questions"`
+	Stop    *struct{}        `subcommand:"stop" description:"Stops the current running game of 21* questions"`
+	History *struct{}        `subcommand:"history" description:"Prints the current running game's Selection history"`
+}
+
+// akiStartOptions are the options of /21q start. Options that aren't given fall back to the guild's settings.
+type akiStartOptions struct {
+	Questions  *int     `option:"questions" description:"Limit the number of questions asked before guessing" min:"1" max:"99"`
+	Confidence *float64 `option:"confidence" description:"Set the confidence threshold needed before guessing" min:"1" max:"99"`
+	Guesses    *int     `option:"guesses" description:"Set the number of guess attempts before giving up" min:"1" max:"5"`
 }
 
 type AkinatorPlugin struct {
-	sessions *threadsafe.Map[string, *akinatorSession]
-	logger   zerolog.Logger
+	sessions  *eris.SessionStore[string, *akinatorSession]
+	newClient AkinatorClientFunc
+	config    AkinatorConfig
+	logger    *slog.Logger
 }
 
-func Akinator(logger zerolog.Logger) *AkinatorPlugin {
-	return &AkinatorPlugin{
-		sessions: threadsafe.NewMap[string, *akinatorSession](),
-		logger:   logger.With().Str("plugin", "akinator").Logger(),
+// Akinator returns the plugin, which plays every game with a client created by newClient.
+func Akinator(logger *slog.Logger, newClient AkinatorClientFunc) *AkinatorPlugin {
+	a := &AkinatorPlugin{
+		newClient: newClient,
+		config: AkinatorConfig{
+			Questions:  21,
+			Confidence: 85.0,
+			Guesses:    3,
+		},
+		logger: logger.With(slog.String("plugin", "akinator")),
 	}
+	a.sessions = eris.NewSessionStore[string, *akinatorSession](akiSessionTTL, a.expire)
+
+	return a
 }
 
 func (a *AkinatorPlugin) Name() string {
@@ -61,475 +145,525 @@ func (a *AkinatorPlugin) Description() string {
 }
 
 func (a *AkinatorPlugin) Handlers() map[string]any {
-	handlers := make(map[string]any)
-
-	handlers["aki_start_handler"] = func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		switch i.Type {
-		case discordgo.InteractionApplicationCommand:
-			applicationCommandData := i.ApplicationCommandData()
-			options, ok := utils.GetApplicationCommandOption(applicationCommandData, "21q", "start")
-			if !ok {
-				return
-			}
+	return nil
+}
 
-			// Set defaults and try and fetch options
-			questionLimit := 21
-			confidenceThreshold := 85.0
-			maxGuesses := 3
-			for _, option := range options.Options {
-				if option.Name == "questions" {
-					v, _ := option.Value.(float64)
-					questionLimit = int(v)
-				}
-				if option.Name == "confidence" {
-					v, _ := option.Value.(float64)
-					confidenceThreshold = v
-				}
-				if option.Name == "guesses" {
-					v, _ := option.Value.(float64)
-					maxGuesses = int(v)
-				}
-			}
+func (a *AkinatorPlugin) Routes() map[string]eris.HandlerFunc {
+	routes := make(map[string]eris.HandlerFunc)
 
-			userId := utils.GetInteractionUserId(i.Interaction)
+	routes["21q start"] = a.start
+	routes["21q history"] = a.history
+	routes["21q stop"] = a.stop
 
-			a.logger.Debug().Str("user_id", userId).
-				Interface("command", applicationCommandData).Msg("user invoked slash command")
+	return routes
+}
 
-			// Check if the user already has a game running
-			if _, ok := a.sessions.Get(userId); ok {
-				utils.InteractionResponse(s, i.Interaction).Ephemeral().
-					Message("Finish you current game first!").SendWithLog(a.logger)
-				return
-			}
+func (a *AkinatorPlugin) start(req *eris.Request) error {
+	s, i := req.Session, req.Interaction
 
-			utils.InteractionResponse(s, i.Interaction).
-				Type(discordgo.InteractionResponseDeferredChannelMessageWithSource).SendWithLog(a.logger)
+	// Set defaults from the guild's settings and try and fetch options
+	questionLimit, _ := req.Setting("questions").(int)
+	confidenceThreshold, _ := req.Setting("confidence").(float64)
+	maxGuesses, _ := req.Setting("guesses").(int)
 
-			gameSession, err := newAkinatorSession(questionLimit, confidenceThreshold, maxGuesses)
-			if err != nil {
-				a.logger.Error().Err(err).Msg("failed to create akinator session")
-				utils.InteractionResponse(s, i.Interaction).Message("Something went wrong.").
-					Flags(discordgo.MessageFlagsEphemeral).EditWithLog(a.logger)
-				return
-			}
-			gameSession.ownerId = userId
-			gameSession.interaction = i.Interaction
-			gameSession.state = akiStateThemeSelection
-
-			a.sessions.Set(userId, gameSession)
-
-			// Update the interaction with the initial theme selection
-			utils.InteractionResponse(s, i.Interaction).Message("Select a theme").
-				Components(gameSession.themeButtons(userId, true)).EditWithLog(a.logger)
-
-			return
-		case discordgo.InteractionMessageComponent:
-			messageComponentData := i.MessageComponentData()
-			if strings.HasPrefix(messageComponentData.CustomID, "21q_theme") {
-				gameSession, selection, ok := a.getGameSession(s, i, akiStateThemeSelection)
-				if !ok {
-					return
-				}
-
-				// If we got this far without returning then let the user know we're thinking
-				utils.InteractionResponse(s, i.Interaction).Type(discordgo.InteractionResponseDeferredMessageUpdate).
-					SendWithLog(a.logger)
-
-				gameSession.state = akiStateProcessing
-				utils.InteractionResponse(s, i.Interaction).
-					Message("<a:loadingdots:1011445769590554684> Starting game...").
-					Components(gameSession.themeButtons(gameSession.ownerId, false)).EditWithLog(a.logger)
-
-				themeIndex, err := strconv.Atoi(selection)
-				if err != nil || themeIndex < 0 || themeIndex >= len(gameSession.themes) {
-					a.logger.Error().Err(err).Str("theme_index", selection).Msg("unexpected theme index received")
-					utils.InteractionResponse(s, i.Interaction).Ephemeral().Message("Something went wrong.").
-						FollowUpCreate()
-					return
-				}
-
-				a.logger.Debug().Str("user_id", utils.GetInteractionUserId(i.Interaction)).
-					Str("theme", gameSession.themes[themeIndex].Name).Msg("user selected theme")
-
-				// Start the game with the theme of choice
-				if _, err = gameSession.client.NewGame(gameSession.themes[themeIndex]); err != nil {
-					a.logger.Error().Err(err).Msg("could not start game")
-					utils.InteractionResponse(s, i.Interaction).Ephemeral().Message("Something went wrong.").
-						FollowUpCreate()
-					return
-				}
-
-				utils.InteractionResponse(s, i.Interaction).Message(gameSession.questionStr()).
-					Components(gameSession.questionButtons(true)).EditWithLog(a.logger)
+	var options akiStartOptions
+	if err := req.Decode(&options); err != nil {
+		return err
+	}
+	if options.Questions != nil {
+		questionLimit = *options.Questions
+	}
+	if options.Confidence != nil {
+		confidenceThreshold = *options.Confidence
+	}
+	if options.Guesses != nil {
+		maxGuesses = *options.Guesses
+	}
 
-				gameSession.state = akiStateAnswerSelection
-
-				return
-			} else if strings.HasPrefix(messageComponentData.CustomID, "21q_answer_") {
-				gameSession, selection, ok := a.getGameSession(s, i, akiStateAnswerSelection)
-				if !ok {
-					return
-				}
-
-				utils.InteractionResponse(s, i.Interaction).Type(discordgo.InteractionResponseDeferredMessageUpdate).
-					SendWithLog(a.logger)
-
-				gameSession.state = akiStateProcessing
-
-				// Update response to show thinking and disable the buttons
-				utils.InteractionResponse(s, i.Interaction).
-					Message("<a:loadingdots:1011445769590554684> George Tuney is thinking...").
-					Components(gameSession.questionButtons(false)).EditWithLog(a.logger)
-
-				answer, err := strconv.Atoi(selection)
-				if err != nil {
-					a.logger.Error().Err(err).Str("answer_index", selection).Msg("unexpected answer index received")
-					utils.InteractionResponse(s, i.Interaction).Message(":x: Something went wrong.").
-						FollowUpCreate()
-					return
-				}
-
-				a.logger.Debug().Str("user_id", utils.GetInteractionUserId(i.Interaction)).
-					Str("question", gameSession.client.Question()).Int("response", answer).
-					Msg("user selected response to question")
-
-				// Submit the answer to the client and fetch the new question
-				if _, err = gameSession.client.Answer(answer); err != nil {
-					a.logger.Error().Err(err).Msg("failed to fetch answer")
-					utils.InteractionResponse(s, i.Interaction).Ephemeral().Message("Something went wrong.").
-						FollowUpCreate()
-					return
-				}
-
-				// End state check
-				if (gameSession.client.Step()+1 > gameSession.questionLimit ||
-					gameSession.client.Progress() >= gameSession.confidenceThreshold) && gameSession.guessCooldown <= 0 {
-
-					utils.InteractionResponse(s, i.Interaction).Components(gameSession.questionButtons(false)).
-						EditWithLog(a.logger)
-
-					gameSession.state = akiStateProcessing
-					// Get the first guess available to the client that hasn't been guessed before
-					guess, ok := gameSession.getGuess()
-					if !ok {
-						// If there are no guesses, and we're already at our wits end, just give up
-						if gameSession.currentGuesses >= gameSession.maxGuesses || gameSession.client.Step()+1 > 99 {
-							utils.InteractionResponse(s, gameSession.interaction).DeleteWithLog(a.logger)
-							utils.InteractionResponse(s, i.Interaction).Components().FollowUpEdit(gameSession.guessMessageId)
-							utils.InteractionResponse(s, i.Interaction).Message("I give up. You win :disappointed:").FollowUpCreate()
-							a.sessions.Delete(gameSession.ownerId)
-						} else {
-							// Otherwise let's just roll it back and pretend like nothing happened hehe
-							a.logger.Debug().Str("game_id", gameSession.ownerId).
-								Msg("end state reached but no new guesses found")
-
-							_ = gameSession.client.Undo()
-							gameSession.guessCooldown = 3
-
-							utils.InteractionResponse(s, gameSession.interaction).Message(gameSession.questionStr()).
-								Components(gameSession.questionButtons(true)).EditWithLog(a.logger)
-
-							gameSession.state = akiStateAnswerSelection
-						}
-						return
-					}
-
-					// Send the user our guess
-					embed := utils.MessageEmbed().Title(guess.name).Image(guess.imageUrl).Build()
-					message, err := utils.InteractionResponse(s, i.Interaction).Message("You're thinking of...").
-						Embeds(embed).Components(gameSession.guessButtons(true)).FollowUpCreate()
-					if err != nil {
-						a.logger.Error().Err(err).Msg("failed to send guess as followup message")
-						utils.InteractionResponse(s, i.Interaction).Components().
-							Message("Something went wrong.").FollowUpCreate()
-						a.cleanupSession(s, gameSession.ownerId)
-						return
-					}
-
-					a.logger.Debug().Str("user_id", utils.GetInteractionUserId(i.Interaction)).
-						Str("guess", guess.name).Msg("guess sent to user")
-
-					// Update internal state to await for the user response to guess
-					gameSession.interaction = i.Interaction
-					gameSession.guessMessageId = message.ID
-					gameSession.currentGuesses += 1
-					gameSession.state = akiStateGuessSelection
-					gameSession.previousGuesses = append(gameSession.previousGuesses, guess)
-
-					return
-				}
-
-				// We're not in an end state, so let's update the message with the new question and continue
-				utils.InteractionResponse(s, i.Interaction).Message(gameSession.questionStr()).
-					Components(gameSession.questionButtons(true)).EditWithLog(a.logger)
+	userId := utils.GetInteractionUserId(i.Interaction)
 
-				gameSession.guessCooldown--
-				gameSession.state = akiStateAnswerSelection
-
-				return
-			} else if strings.HasPrefix(messageComponentData.CustomID, "21q_guess_") {
-				gameSession, selection, ok := a.getGameSession(s, i, akiStateGuessSelection)
-				if !ok {
-					return
-				}
-
-				utils.InteractionResponse(s, i.Interaction).
-					Type(discordgo.InteractionResponseDeferredMessageUpdate).SendWithLog(a.logger)
-
-				a.logger.Debug().Str("user_id", utils.GetInteractionUserId(i.Interaction)).
-					Str("response", selection).Msg("user selected response to guess")
-
-				if selection == "yes" {
-					// Woo the guess was marked as correct. Time to celebrate and clean up.
-					utils.InteractionResponse(s, gameSession.interaction).DeleteWithLog(a.logger)
-					_, _ = utils.InteractionResponse(s, gameSession.interaction).Components().
-						FollowUpEdit(gameSession.guessMessageId)
-					utils.InteractionResponse(s, i.Interaction).Message(":tada:").FollowUpCreate()
-					a.sessions.Delete(gameSession.ownerId)
-				} else if selection == "no" {
-					// The guess was wrong, so let's check our current status and determine if we should give up.
-					if gameSession.currentGuesses >= gameSession.maxGuesses || gameSession.client.Step()+1 > 99 {
-						utils.InteractionResponse(s, gameSession.interaction).DeleteWithLog(a.logger)
-						utils.InteractionResponse(s, i.Interaction).Components().FollowUpEdit(gameSession.guessMessageId)
-						utils.InteractionResponse(s, i.Interaction).Message("I give up. You win :disappointed:").FollowUpCreate()
-						a.sessions.Delete(gameSession.ownerId)
-					} else {
-						err := utils.InteractionResponse(s, gameSession.interaction).FollowUpDelete(gameSession.guessMessageId)
-						if err != nil {
-							a.logger.Error().Err(err).Str("message_id", gameSession.guessMessageId).
-								Msg("failed to delete follow up message")
-						}
-
-						_ = gameSession.client.Undo()
-						gameSession.guessMessageId = ""
-						gameSession.guessCooldown = 3
-						gameSession.questionLimit += 21
-
-						utils.InteractionResponse(s, gameSession.interaction).Message(gameSession.questionStr()).
-							Components(gameSession.questionButtons(true)).EditWithLog(a.logger)
-
-						gameSession.state = akiStateAnswerSelection
-					}
-				}
-			}
+	unlock := a.sessions.Lock(userId)
+	defer unlock()
+
+	// Check if the user already has a game running
+	if _, ok := a.sessions.Get(userId); ok {
+		utils.InteractionResponse(s, i.Interaction).Ephemeral().
+			Message("Finish you current game first!").SendWithLog(a.logger)
+		return nil
+	}
+
+	utils.InteractionResponse(s, i.Interaction).
+		Type(discordgo.InteractionResponseDeferredChannelMessageWithSource).SendWithLog(a.logger)
+
+	client, err := a.newClient()
+	if err != nil {
+		a.logger.Error("failed to create akinator session",
+			slog.String("error", err.Error()),
+		)
+		utils.InteractionResponse(s, i.Interaction).Message("Something went wrong.").
+			Flags(discordgo.MessageFlagsEphemeral).EditWithLog(a.logger)
+		return nil
+	}
+	gameSession := newAkinatorSession(client, questionLimit, confidenceThreshold, maxGuesses)
+	gameSession.discord = s
+	gameSession.ownerId = userId
+	gameSession.interaction = i.Interaction
+	gameSession.state = akiStateThemeSelection
+
+	a.sessions.Set(userId, gameSession)
+
+	// Update the interaction with the initial theme selection
+	utils.InteractionResponse(s, i.Interaction).Message("Select a theme").
+		Components(gameSession.themeButtons(userId, true)).EditWithLog(a.logger)
+
+	return nil
+}
+
+func (a *AkinatorPlugin) history(req *eris.Request) error {
+	s, i := req.Session, req.Interaction
+
+	userId := utils.GetInteractionUserId(i.Interaction)
+
+	// The client is only read with the game locked and idle, since a button handler may be using it
+	unlock := a.sessions.Lock(userId)
+	defer unlock()
+
+	gameSession, ok := a.sessions.Peek(userId)
+	if !ok {
+		utils.InteractionResponse(s, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
+			Message("No game is currently running.").SendWithLog(a.logger)
+		return nil
+	}
+	if gameSession.state == akiStateProcessing {
+		utils.InteractionResponse(s, i.Interaction).Ephemeral().Message("Please wait, I'm thinking...").
+			SendWithLog(a.logger)
+		return nil
+	}
+
+	responseStr := ""
+	if selections := gameSession.client.History(); len(selections) == 0 {
+		responseStr = "No history yet"
+	} else {
+		for index, selection := range selections {
+			responseStr += fmt.Sprintf("%d) %s %s\n", index+1, selection.Question, selection.Answer)
 		}
 	}
 
-	handlers["aki_history_handler"] = func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		switch i.Type {
-		case discordgo.InteractionApplicationCommand:
-			applicationCommandData := i.ApplicationCommandData()
-			if _, ok := utils.GetApplicationCommandOption(applicationCommandData, "21q", "history"); !ok {
-				return
-			}
+	utils.InteractionResponse(s, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
+		Message("```" + responseStr + "```").SendWithLog(a.logger)
 
-			userId := utils.GetInteractionUserId(i.Interaction)
+	return nil
+}
 
-			a.logger.Debug().Str("user_id", userId).
-				Interface("command", applicationCommandData).Msg("user invoked slash command")
+func (a *AkinatorPlugin) stop(req *eris.Request) error {
+	s, i := req.Session, req.Interaction
 
-			gameSession, ok := a.sessions.Get(userId)
-			if !ok {
-				utils.InteractionResponse(s, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
-					Message("No game is currently running.").SendWithLog(a.logger)
-				return
-			}
+	userId := utils.GetInteractionUserId(i.Interaction)
+
+	unlock := a.sessions.Lock(userId)
+	defer unlock()
 
-			responseStr := ""
-			if len(gameSession.client.Selections) == 0 {
-				responseStr = "No history yet"
+	gameSession, ok := a.sessions.Peek(userId)
+	if !ok {
+		utils.InteractionResponse(s, i.Interaction).Ephemeral().
+			Message("No game is currently running.").SendWithLog(a.logger)
+		return nil
+	}
+	if gameSession.state == akiStateProcessing {
+		utils.InteractionResponse(s, i.Interaction).Ephemeral().Message("Please wait, I'm thinking...").
+			SendWithLog(a.logger)
+		return nil
+	}
+
+	a.cleanupSession(s, userId)
+	utils.InteractionResponse(s, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
+		Message(":wave:").SendWithLog(a.logger)
+
+	return nil
+}
+
+func (a *AkinatorPlugin) Components() map[string]eris.HandlerFunc {
+	components := make(map[string]eris.HandlerFunc)
+
+	components["21q_theme_{theme:int}_{owner}"] = a.selectTheme
+	components["21q_answer_{answer:int}_{owner}"] = a.answer
+	components["21q_guess_{guess}_{owner}"] = a.guess
+
+	return components
+}
+
+func (a *AkinatorPlugin) selectTheme(req *eris.Request) error {
+	s, i := req.Session, req.Interaction
+
+	gameSession, ok := a.getGameSession(req, akiStateThemeSelection)
+	if !ok {
+		return nil
+	}
+
+	// A failed request leaves the game where it was, so the user can try again
+	next := akiStateThemeSelection
+	defer func() {
+		a.release(gameSession, next)
+	}()
+
+	// If we got this far without returning then let the user know we're thinking
+	utils.InteractionResponse(s, i.Interaction).Type(discordgo.InteractionResponseDeferredMessageUpdate).
+		SendWithLog(a.logger)
+
+	utils.InteractionResponse(s, i.Interaction).
+		Message("<a:loadingdots:1011445769590554684> Starting game...").
+		Components(gameSession.themeButtons(gameSession.ownerId, false)).EditWithLog(a.logger)
+
+	themes := gameSession.client.Themes()
+	themeIndex := req.IntParam("theme")
+	if themeIndex < 0 || themeIndex >= len(themes) {
+		a.logger.Error("unexpected theme index received",
+			slog.Int("theme_index", themeIndex),
+		)
+		utils.InteractionResponse(s, i.Interaction).Ephemeral().Message("Something went wrong.").
+			FollowUpCreate()
+		return nil
+	}
+
+	a.logger.Debug("user selected theme",
+		slog.String("user_id", utils.GetInteractionUserId(i.Interaction)),
+		slog.String("theme", themes[themeIndex]),
+	)
+
+	// Start the game with the theme of choice
+	if err := gameSession.client.NewGame(themeIndex); err != nil {
+		a.logger.Error("could not start game",
+			slog.String("error", err.Error()),
+		)
+		utils.InteractionResponse(s, i.Interaction).Message("Select a theme").
+			Components(gameSession.themeButtons(gameSession.ownerId, true)).EditWithLog(a.logger)
+		utils.InteractionResponse(s, i.Interaction).Ephemeral().Message("Something went wrong.").
+			FollowUpCreate()
+		return nil
+	}
+
+	utils.InteractionResponse(s, i.Interaction).Message(gameSession.questionStr()).
+		Components(gameSession.questionButtons(true)).EditWithLog(a.logger)
+
+	next = akiStateAnswerSelection
+
+	return nil
+}
+
+func (a *AkinatorPlugin) answer(req *eris.Request) error {
+	s, i := req.Session, req.Interaction
+
+	gameSession, ok := a.getGameSession(req, akiStateAnswerSelection)
+	if !ok {
+		return nil
+	}
+
+	next := akiStateAnswerSelection
+	defer func() {
+		a.release(gameSession, next)
+	}()
+
+	utils.InteractionResponse(s, i.Interaction).Type(discordgo.InteractionResponseDeferredMessageUpdate).
+		SendWithLog(a.logger)
+
+	// Update response to show thinking and disable the buttons
+	utils.InteractionResponse(s, i.Interaction).
+		Message("<a:loadingdots:1011445769590554684> George Tuney is thinking...").
+		Components(gameSession.questionButtons(false)).EditWithLog(a.logger)
+
+	answer := req.IntParam("answer")
+
+	a.logger.Debug("user selected response to question",
+		slog.String("user_id", utils.GetInteractionUserId(i.Interaction)),
+		slog.String("question", gameSession.client.Question()),
+		slog.Int("response", answer),
+	)
+
+	// Submit the answer to the client and fetch the new question
+	if err := gameSession.client.Answer(answer); err != nil {
+		a.logger.Error("failed to fetch answer",
+			slog.String("error", err.Error()),
+		)
+		utils.InteractionResponse(s, i.Interaction).Message(gameSession.questionStr()).
+			Components(gameSession.questionButtons(true)).EditWithLog(a.logger)
+		utils.InteractionResponse(s, i.Interaction).Ephemeral().Message("Something went wrong.").
+			FollowUpCreate()
+		return nil
+	}
+
+	// End state check
+	if (gameSession.client.Step()+1 > gameSession.questionLimit ||
+		gameSession.client.Progress() >= gameSession.confidenceThreshold) && gameSession.guessCooldown <= 0 {
+
+		utils.InteractionResponse(s, i.Interaction).Components(gameSession.questionButtons(false)).
+			EditWithLog(a.logger)
+
+		// Get the first guess available to the client that hasn't been guessed before
+		guess, ok := gameSession.getGuess()
+		if !ok {
+			// If there are no guesses, and we're already at our wits end, just give up
+			if gameSession.currentGuesses >= gameSession.maxGuesses || gameSession.client.Step()+1 > 99 {
+				utils.InteractionResponse(s, gameSession.interaction).DeleteWithLog(a.logger)
+				utils.InteractionResponse(s, i.Interaction).Components().FollowUpEdit(gameSession.guessMessageId)
+				utils.InteractionResponse(s, i.Interaction).Message("I give up. You win :disappointed:").FollowUpCreate()
+				a.sessions.Delete(gameSession.ownerId)
 			} else {
-				for index, selection := range gameSession.client.Selections {
-					responseStr += fmt.Sprintf("%d) %s %s\n", index+1, selection.Question, selection.Answer)
-				}
-			}
+				// Otherwise let's just roll it back and pretend like nothing happened hehe
+				a.logger.Debug("end state reached but no new guesses found",
+					slog.String("game_id", gameSession.ownerId),
+				)
 
-			utils.InteractionResponse(s, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
-				Message("```" + responseStr + "```").SendWithLog(a.logger)
+				_ = gameSession.client.Undo()
+				gameSession.guessCooldown = 3
 
-			return
+				utils.InteractionResponse(s, gameSession.interaction).Message(gameSession.questionStr()).
+					Components(gameSession.questionButtons(true)).EditWithLog(a.logger)
+			}
+			return nil
 		}
 
+		// Send the user our guess
+		embed := utils.MessageEmbed().Title(guess.Name).Image(guess.ImageUrl).Build()
+		message, err := utils.InteractionResponse(s, i.Interaction).Message("You're thinking of...").
+			Embeds(embed).Components(gameSession.guessButtons(true)).FollowUpCreate()
+		if err != nil {
+			a.logger.Error("failed to send guess as followup message",
+				slog.String("error", err.Error()),
+			)
+			utils.InteractionResponse(s, i.Interaction).Components().
+				Message("Something went wrong.").FollowUpCreate()
+			a.cleanupSession(s, gameSession.ownerId)
+			return nil
+		}
+
+		a.logger.Debug("guess sent to user",
+			slog.String("user_id", utils.GetInteractionUserId(i.Interaction)),
+			slog.String("guess", guess.Name),
+		)
+
+		// Update internal state to await for the user response to guess
+		gameSession.interaction = i.Interaction
+		gameSession.guessMessageId = message.ID
+		gameSession.currentGuesses += 1
+		gameSession.previousGuesses = append(gameSession.previousGuesses, guess)
+		next = akiStateGuessSelection
+
+		return nil
 	}
 
-	handlers["aki_stop_handler"] = func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		switch i.Type {
-		case discordgo.InteractionApplicationCommand:
-			applicationCommandData := i.ApplicationCommandData()
-			if _, ok := utils.GetApplicationCommandOption(applicationCommandData, "21q", "stop"); !ok {
-				return
-			}
+	// We're not in an end state, so let's update the message with the new question and continue
+	utils.InteractionResponse(s, i.Interaction).Message(gameSession.questionStr()).
+		Components(gameSession.questionButtons(true)).EditWithLog(a.logger)
 
-			userId := utils.GetInteractionUserId(i.Interaction)
+	gameSession.guessCooldown--
 
-			a.logger.Debug().Str("user_id", userId).
-				Interface("command", applicationCommandData).Msg("user invoked slash command")
+	return nil
+}
 
-			if _, ok := a.sessions.Get(userId); !ok {
-				utils.InteractionResponse(s, i.Interaction).Ephemeral().
-					Message("No game is currently running.").SendWithLog(a.logger)
-				return
+func (a *AkinatorPlugin) guess(req *eris.Request) error {
+	s, i := req.Session, req.Interaction
+
+	gameSession, ok := a.getGameSession(req, akiStateGuessSelection)
+	selection := req.Param("guess")
+	if !ok {
+		return nil
+	}
+
+	next := akiStateGuessSelection
+	defer func() {
+		a.release(gameSession, next)
+	}()
+
+	utils.InteractionResponse(s, i.Interaction).
+		Type(discordgo.InteractionResponseDeferredMessageUpdate).SendWithLog(a.logger)
+
+	a.logger.Debug("user selected response to guess",
+		slog.String("user_id", utils.GetInteractionUserId(i.Interaction)),
+		slog.String("response", selection),
+	)
+
+	if selection == "yes" {
+		// Woo the guess was marked as correct. Time to celebrate and clean up.
+		utils.InteractionResponse(s, gameSession.interaction).DeleteWithLog(a.logger)
+		_, _ = utils.InteractionResponse(s, gameSession.interaction).Components().
+			FollowUpEdit(gameSession.guessMessageId)
+		utils.InteractionResponse(s, i.Interaction).Message(":tada:").FollowUpCreate()
+		a.sessions.Delete(gameSession.ownerId)
+	} else if selection == "no" {
+		// The guess was wrong, so let's check our current status and determine if we should give up.
+		if gameSession.currentGuesses >= gameSession.maxGuesses || gameSession.client.Step()+1 > 99 {
+			utils.InteractionResponse(s, gameSession.interaction).DeleteWithLog(a.logger)
+			utils.InteractionResponse(s, i.Interaction).Components().FollowUpEdit(gameSession.guessMessageId)
+			utils.InteractionResponse(s, i.Interaction).Message("I give up. You win :disappointed:").FollowUpCreate()
+			a.sessions.Delete(gameSession.ownerId)
+		} else {
+			err := utils.InteractionResponse(s, gameSession.interaction).FollowUpDelete(gameSession.guessMessageId)
+			if err != nil {
+				a.logger.Error("failed to delete follow up message",
+					slog.String("error", err.Error()),
+					slog.String("message_id", gameSession.guessMessageId),
+				)
 			}
 
-			a.cleanupSession(s, userId)
-			utils.InteractionResponse(s, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
-				Message(":wave:").SendWithLog(a.logger)
+			_ = gameSession.client.Undo()
+			gameSession.guessMessageId = ""
+			gameSession.guessCooldown = 3
+			gameSession.questionLimit += 21
 
-			return
+			utils.InteractionResponse(s, gameSession.interaction).Message(gameSession.questionStr()).
+				Components(gameSession.questionButtons(true)).EditWithLog(a.logger)
+
+			next = akiStateAnswerSelection
 		}
 	}
 
-	return handlers
+	return nil
 }
 
 func (a *AkinatorPlugin) Commands() map[string]*discordgo.ApplicationCommand {
 	commands := make(map[string]*discordgo.ApplicationCommand)
 
-	minQuestions := float64(1)
-	maxQuestions := float64(99)
-	minConfidence := float64(1)
-	maxConfidence := 99.0
-	minGuess := float64(1)
-	maxGuess := float64(5)
-
-	commands["aki_cmd"] = &discordgo.ApplicationCommand{
-		Name:        "21q",
-		Description: "21 questions like game",
-		Options: []*discordgo.ApplicationCommandOption{
-			{
-				Name:        "start",
-				Description: "Starts a new game of 21* questions",
-				Type:        discordgo.ApplicationCommandOptionSubCommand,
-				Options: []*discordgo.ApplicationCommandOption{
-					{
-						Name:        "questions",
-						Description: "Limit the number of questions asked before guessing (default = 21)",
-						Type:        discordgo.ApplicationCommandOptionInteger,
-						Required:    false,
-						MinValue:    &minQuestions,
-						MaxValue:    maxQuestions,
-					},
-					{
-						Name:        "confidence",
-						Description: "Set the confidence threshold needed before guessing (default = 85.0)",
-						Type:        discordgo.ApplicationCommandOptionNumber,
-						Required:    false,
-						MinValue:    &minConfidence,
-						MaxValue:    maxConfidence,
-					},
-					{
-						Name:        "guesses",
-						Description: "Set the number of guess attempts before giving up (default = 3)",
-						Type:        discordgo.ApplicationCommandOptionInteger,
-						Required:    false,
-						MinValue:    &minGuess,
-						MaxValue:    maxGuess,
-					},
-				},
-			},
-			{
-				Name:        "stop",
-				Description: "Stops the current running game of 21* questions",
-				Type:        discordgo.ApplicationCommandOptionSubCommand,
-			},
-			{
-				Name:        "history",
-				Description: "Prints the current running game's Selection history",
-				Type:        discordgo.ApplicationCommandOptionSubCommand,
-			},
+	commands["aki_cmd"] = eris.MustCommandFromStruct("21q", "21 questions like game", akiCommand{})
+
+	return commands
+}
+
+// Settings lets guild admins override the configured game defaults.
+func (a *AkinatorPlugin) Settings() []eris.Setting {
+	return []eris.Setting{
+		{
+			Name:        "questions",
+			Description: "Number of questions asked before guessing when not given",
+			Type:        eris.SettingInt,
+			Default:     a.config.Questions,
+			Min:         1,
+			Max:         99,
+		},
+		{
+			Name:        "confidence",
+			Description: "Confidence needed before guessing when not given",
+			Type:        eris.SettingNumber,
+			Default:     a.config.Confidence,
+			Min:         1,
+			Max:         99,
+		},
+		{
+			Name:        "guesses",
+			Description: "Number of guesses before giving up when not given",
+			Type:        eris.SettingInt,
+			Default:     a.config.Guesses,
+			Min:         1,
+			Max:         5,
 		},
 	}
+}
 
-	return commands
+// Config lets the game defaults be set from the "plugins.akinator" config section.
+func (a *AkinatorPlugin) Config() any {
+	return &a.config
 }
 
 func (a *AkinatorPlugin) Intents() []discordgo.Intent {
 	return nil
 }
 
-func (a *AkinatorPlugin) getGameSession(s *discordgo.Session, i *discordgo.InteractionCreate, targetState int) (*akinatorSession, string, bool) {
-	messageComponentData := i.MessageComponentData()
+// Close drops any games still in progress.
+func (a *AkinatorPlugin) Close(_ context.Context) error {
+	a.sessions.Clear()
 
-	idSplit := strings.Split(messageComponentData.CustomID, "_")
-	if len(idSplit) != 4 {
-		utils.InteractionResponse(s, i.Interaction).Ephemeral().Message("Something went wrong.").
-			SendWithLog(a.logger)
-		return nil, "", false
-	}
+	return nil
+}
 
-	selection := idSplit[2]
-	ownerId := idSplit[3]
-	userId := utils.GetInteractionUserId(i.Interaction)
+func (a *AkinatorPlugin) getGameSession(req *eris.Request, targetState int) (*akinatorSession, bool) {
+	s, i := req.Session, req.Interaction
 
-	a.logger.Debug().Str("user_id", userId).
-		Interface("component", messageComponentData).Msg("user interacted with component")
+	ownerId := req.Param("owner")
+	userId := utils.GetInteractionUserId(i.Interaction)
 
 	if userId != ownerId {
 		utils.InteractionResponse(s, i.Interaction).Ephemeral().Message("This isn't your game :bell:").
 			SendWithLog(a.logger)
-		return nil, "", false
+		return nil, false
 	}
 
+	// The state is checked and moved to processing with the game locked, so that a double click isn't handled twice
+	unlock := a.sessions.Lock(userId)
+	defer unlock()
+
 	gameSession, ok := a.sessions.Get(userId)
 	if !ok {
 		utils.InteractionResponse(s, i.Interaction).Ephemeral().Message("Game no longer exists.").
 			SendWithLog(a.logger)
-		return nil, "", false
+		return nil, false
 	}
 
 	switch gameSession.state {
 	case targetState:
-		break
+		gameSession.state = akiStateProcessing
 	case akiStateProcessing:
 		utils.InteractionResponse(s, i.Interaction).Ephemeral().Message("Please wait, I'm thinking...").
 			SendWithLog(a.logger)
-		return nil, "", false
+		return nil, false
 	default:
 		utils.InteractionResponse(s, i.Interaction).Ephemeral().Message("Invalid button for game state.").
 			SendWithLog(a.logger)
-		return nil, "", false
+		return nil, false
 	}
 
-	return gameSession, selection, true
+	return gameSession, true
 }
 
+// release moves a game that was being processed on to the next state. The game is locked so that getGameSession, which
+// checks the state, sees everything the handler changed.
+func (a *AkinatorPlugin) release(gameSession *akinatorSession, next int) {
+	unlock := a.sessions.Lock(gameSession.ownerId)
+	defer unlock()
+
+	gameSession.state = next
+}
+
+// cleanupSession deletes the messages of the game and drops it.
 func (a *AkinatorPlugin) cleanupSession(session *discordgo.Session, id string) {
-	if gameSession, ok := a.sessions.Get(id); ok {
-		if gameSession.interaction != nil {
-			utils.InteractionResponse(session, gameSession.interaction).DeleteWithLog(a.logger)
-			if gameSession.guessMessageId != "" {
-				_ = utils.InteractionResponse(session, gameSession.interaction).FollowUpDelete(gameSession.guessMessageId)
-			}
-		}
+	if gameSession, ok := a.sessions.Peek(id); ok {
+		a.deleteMessages(session, gameSession)
 		a.sessions.Delete(id)
 	}
 }
 
-func newAkinatorSession(questionLimit int, confidenceThreshold float64, maxGuesses int) (*akinatorSession, error) {
-	client, err := athena.NewClient()
-	if err != nil {
-		return nil, err
+// expire cleans up the messages of a game that was abandoned.
+func (a *AkinatorPlugin) expire(ownerId string, gameSession *akinatorSession) {
+	if gameSession.discord != nil {
+		a.deleteMessages(gameSession.discord, gameSession)
 	}
 
-	themes, err := athena.GetThemes()
-	if err != nil {
-		return nil, err
+	a.logger.Debug("game expired",
+		slog.String("user_id", ownerId),
+	)
+}
+
+func (a *AkinatorPlugin) deleteMessages(session *discordgo.Session, gameSession *akinatorSession) {
+	if gameSession.interaction != nil {
+		utils.InteractionResponse(session, gameSession.interaction).DeleteWithLog(a.logger)
+		if gameSession.guessMessageId != "" {
+			_ = utils.InteractionResponse(session, gameSession.interaction).FollowUpDelete(gameSession.guessMessageId)
+		}
 	}
+}
 
+func newAkinatorSession(client AkinatorClient, questionLimit int, confidenceThreshold float64,
+	maxGuesses int) *akinatorSession {
 	session := akinatorSession{
 		client:              client,
-		themes:              themes,
 		state:               akiStateNil,
 		questionLimit:       questionLimit,
 		confidenceThreshold: confidenceThreshold,
 		currentGuesses:      0,
 		maxGuesses:          maxGuesses,
 		guessCooldown:       0,
-		previousGuesses:     []akinatorGuess{{name: "Ashley Wsfd"}},
+		previousGuesses:     []AkinatorGuess{{Name: "Ashley Wsfd"}},
 	}
 
-	return &session, nil
+	return &session
 }
 
 func (a *akinatorSession) questionStr() string {
@@ -549,8 +683,8 @@ func (a *akinatorSession) questionButtons(enabled bool) discordgo.ActionsRow {
 
 func (a *akinatorSession) themeButtons(userId string, enabled bool) discordgo.ActionsRow {
 	var actionRowBuilder utils.ActionsRowBuilder
-	for index, theme := range a.themes {
-		themeName := []rune(strings.ToLower(theme.Name))
+	for index, theme := range a.client.Themes() {
+		themeName := []rune(strings.ToLower(theme))
 		if len(themeName) > 0 {
 			themeName[0] = unicode.ToUpper(themeName[0])
 		}
@@ -572,31 +706,26 @@ func (a *akinatorSession) guessButtons(enabled bool) discordgo.ActionsRow {
 	return actionRowBuilder.Build()
 }
 
-func (a *akinatorSession) getGuess() (akinatorGuess, bool) {
-	response, err := a.client.ListGuesses()
+func (a *akinatorSession) getGuess() (AkinatorGuess, bool) {
+	guesses, err := a.client.Guesses()
 	if err != nil {
-		return akinatorGuess{}, false
-	}
-
-	if len(response.Parameters.Elements) == 0 {
-		return akinatorGuess{}, false
+		return AkinatorGuess{}, false
 	}
 
-	var newGuess akinatorGuess
-	for _, guess := range response.Parameters.Elements {
+	var newGuess AkinatorGuess
+	for _, guess := range guesses {
 		previouslyGuessed := false
 		for _, previousGuess := range a.previousGuesses {
-			if guess.Element.Name == previousGuess.name {
+			if guess.Name == previousGuess.Name {
 				previouslyGuessed = true
 				break
 			}
 		}
 		if !previouslyGuessed {
-			newGuess.name = guess.Element.Name
-			newGuess.imageUrl = guess.Element.AbsolutePicturePath
+			newGuess = guess
 			break
 		}
 	}
 
-	return newGuess, newGuess.name != ""
+	return newGuess, newGuess.Name != ""
 }
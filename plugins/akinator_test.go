@@ -0,0 +1,246 @@
+package plugins_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris/eristest"
+	"github.com/olympus-go/eris/plugins"
+)
+
+// fakeAkinator is an AkinatorClient that is confident enough to guess after a single answer. The first answerFailures
+// answers fail.
+type fakeAkinator struct {
+	step           int
+	history        []plugins.AkinatorSelection
+	answerFailures int
+}
+
+func (f *fakeAkinator) Themes() []string {
+	return []string{"CHARACTERS", "OBJECTS"}
+}
+
+func (f *fakeAkinator) NewGame(_ int) error {
+	return nil
+}
+
+func (f *fakeAkinator) Question() string {
+	return fmt.Sprintf("Question %d", f.step+1)
+}
+
+func (f *fakeAkinator) Answers() []string {
+	return []string{"Yes", "No"}
+}
+
+func (f *fakeAkinator) Answer(answer int) error {
+	if f.answerFailures > 0 {
+		f.answerFailures--
+		return errors.New("backend unavailable")
+	}
+
+	f.history = append(f.history, plugins.AkinatorSelection{Question: f.Question(), Answer: f.Answers()[answer]})
+	f.step++
+
+	return nil
+}
+
+func (f *fakeAkinator) Undo() error {
+	if f.step > 0 {
+		f.step--
+		f.history = f.history[:f.step]
+	}
+
+	return nil
+}
+
+func (f *fakeAkinator) Step() int {
+	return f.step
+}
+
+func (f *fakeAkinator) Progress() float64 {
+	return float64(f.step) * 90
+}
+
+func (f *fakeAkinator) History() []plugins.AkinatorSelection {
+	return f.history
+}
+
+func (f *fakeAkinator) Guesses() ([]plugins.AkinatorGuess, error) {
+	return []plugins.AkinatorGuess{{Name: "Ada Lovelace"}}, nil
+}
+
+// akinator returns the plugin playing every game with the client.
+func akinator(client plugins.AkinatorClient) *plugins.AkinatorPlugin {
+	return plugins.Akinator(discardLogger(), func() (plugins.AkinatorClient, error) {
+		return client, nil
+	})
+}
+
+// waitForMessage waits until messages returns one containing the text, failing the test if it never does.
+func waitForMessage(t *testing.T, server *eristest.Server, text string, messages func() []*discordgo.Message) {
+	t.Helper()
+
+	if !server.WaitFor(func() bool { return containsMessage(messages(), text) }) {
+		t.Fatalf("no message containing %q was sent", text)
+	}
+}
+
+// original returns a function listing the original response of the interaction, if it was sent.
+func original(server *eristest.Server, interaction *discordgo.Interaction) func() []*discordgo.Message {
+	return func() []*discordgo.Message {
+		if message := server.Original(interaction.Token); message != nil {
+			return []*discordgo.Message{message}
+		}
+		return nil
+	}
+}
+
+// followups returns a function listing the followup messages of the interaction.
+func followups(server *eristest.Server, interaction *discordgo.Interaction) func() []*discordgo.Message {
+	return func() []*discordgo.Message {
+		return server.Followups(interaction.Token)
+	}
+}
+
+// startAkinator starts a game for user 500 and picks the first theme.
+func startAkinator(t *testing.T, server *eristest.Server) {
+	t.Helper()
+
+	start := server.InteractionCreate(eristest.SlashCommand("500", "21q", eristest.SubCommand("start")))
+	waitForMessage(t, server, "Select a theme", original(server, start))
+
+	theme := server.InteractionCreate(eristest.Component("500", "21q_theme_0_500"))
+	waitForMessage(t, server, "1) Question 1", original(server, theme))
+}
+
+func TestAkinatorCommand(t *testing.T) {
+	server, _ := newPluginBot(t, akinator(&fakeAkinator{}))
+
+	var command *discordgo.ApplicationCommand
+	for _, registered := range server.Commands("") {
+		if registered.Name == "21q" {
+			command = registered
+		}
+	}
+	if command == nil {
+		t.Fatal("21q isn't registered")
+	}
+
+	subcommands := make(map[string]*discordgo.ApplicationCommandOption)
+	for _, option := range command.Options {
+		if option.Type != discordgo.ApplicationCommandOptionSubCommand {
+			t.Errorf("option %q is of type %s, want a sub command", option.Name, option.Type)
+		}
+		subcommands[option.Name] = option
+	}
+	for _, name := range []string{"start", "stop", "history"} {
+		if subcommands[name] == nil {
+			t.Errorf("sub command %q is missing", name)
+		}
+	}
+
+	if start := subcommands["start"]; start != nil {
+		for _, option := range start.Options {
+			if option.Required {
+				t.Errorf("start option %q is required, want it to fall back to the settings", option.Name)
+			}
+			if option.MinValue == nil || *option.MinValue != 1 {
+				t.Errorf("start option %q has min %v, want 1", option.Name, option.MinValue)
+			}
+		}
+	}
+}
+
+func TestAkinatorWithoutGame(t *testing.T) {
+	server, _ := newPluginBot(t, akinator(&fakeAkinator{}))
+
+	for _, subcommand := range []string{"stop", "history"} {
+		interaction := server.InteractionCreate(eristest.SlashCommand("500", "21q", eristest.SubCommand(subcommand)))
+		response := server.WaitForResponse(interaction.ID)
+
+		if response.Data.Content != "No game is currently running." {
+			t.Errorf("%s response = %q, want %q", subcommand, response.Data.Content, "No game is currently running.")
+		}
+		if response.Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+			t.Errorf("%s response isn't ephemeral", subcommand)
+		}
+	}
+}
+
+func TestAkinatorButtons(t *testing.T) {
+	server, _ := newPluginBot(t, akinator(&fakeAkinator{}))
+
+	for _, test := range []struct {
+		name     string
+		customId string
+		want     string
+	}{
+		{"someone else's game", "21q_answer_0_600", "This isn't your game :bell:"},
+		{"no game", "21q_answer_0_500", "Game no longer exists."},
+		{"guess without game", "21q_guess_yes_500", "Game no longer exists."},
+	} {
+		interaction := server.InteractionCreate(eristest.Component("500", test.customId))
+		if response := server.WaitForResponse(interaction.ID); response.Data.Content != test.want {
+			t.Errorf("%s: response = %q, want %q", test.name, response.Data.Content, test.want)
+		}
+	}
+}
+
+func TestAkinatorGame(t *testing.T) {
+	server, _ := newPluginBot(t, akinator(&fakeAkinator{}))
+	startAkinator(t, server)
+
+	answer := server.InteractionCreate(eristest.Component("500", "21q_answer_0_500"))
+	waitForMessage(t, server, "You're thinking of...", followups(server, answer))
+
+	history := server.InteractionCreate(eristest.SlashCommand("500", "21q", eristest.SubCommand("history")))
+	if response := server.WaitForResponse(history.ID); !strings.Contains(response.Data.Content, "1) Question 1 Yes") {
+		t.Errorf("history = %q, want the answered question", response.Data.Content)
+	}
+
+	guess := server.InteractionCreate(eristest.Component("500", "21q_guess_yes_500"))
+	waitForMessage(t, server, ":tada:", followups(server, guess))
+}
+
+func TestAkinatorAnswerFailure(t *testing.T) {
+	server, _ := newPluginBot(t, akinator(&fakeAkinator{answerFailures: 1}))
+	startAkinator(t, server)
+
+	failed := server.InteractionCreate(eristest.Component("500", "21q_answer_0_500"))
+	waitForMessage(t, server, "Something went wrong.", followups(server, failed))
+
+	// The failed answer doesn't lock the user out of the game. The handler may still be returning when the followup
+	// arrives, so answering again is retried for as long as the game is busy.
+	deadline := time.Now().Add(server.Timeout)
+	for {
+		retry := server.InteractionCreate(eristest.Component("500", "21q_answer_0_500"))
+		response := server.WaitForResponse(retry.ID)
+		if response.Type == discordgo.InteractionResponseDeferredMessageUpdate {
+			waitForMessage(t, server, "You're thinking of...", followups(server, retry))
+			return
+		}
+		if response.Data == nil || response.Data.Content != "Please wait, I'm thinking..." || time.Now().After(deadline) {
+			t.Fatalf("answer after a failure = %+v, want the game to go on", response.Data)
+		}
+	}
+}
+
+func TestAkinatorConfigValidate(t *testing.T) {
+	for _, test := range []struct {
+		config plugins.AkinatorConfig
+		valid  bool
+	}{
+		{plugins.AkinatorConfig{Questions: 21, Confidence: 85, Guesses: 3}, true},
+		{plugins.AkinatorConfig{Questions: 0, Confidence: 85, Guesses: 3}, false},
+		{plugins.AkinatorConfig{Questions: 21, Confidence: 100, Guesses: 3}, false},
+		{plugins.AkinatorConfig{Questions: 21, Confidence: 85, Guesses: 6}, false},
+	} {
+		if err := test.config.Validate(); (err == nil) != test.valid {
+			t.Errorf("Validate(%+v) = %v, want valid %t", test.config, err, test.valid)
+		}
+	}
+}
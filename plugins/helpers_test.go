@@ -0,0 +1,36 @@
+package plugins_test
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+// discardLogger returns a logger that drops everything, keeping the test output readable.
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// newPluginBot creates a bot connected to a new server with the plugin added. The bot is stopped when the test
+// completes.
+func newPluginBot(t *testing.T, plugin eris.Plugin) (*eristest.Server, *eris.Bot) {
+	t.Helper()
+
+	server := eristest.NewServer(t)
+	bot, err := server.NewBot(eris.Config{}, discardLogger().Handler())
+	if err != nil {
+		t.Fatalf("failed to create bot: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = bot.Stop()
+	})
+
+	if err = bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin %q: %v", plugin.Name(), err)
+	}
+
+	return server, bot
+}
@@ -1,14 +1,13 @@
 package plugins
 
 import (
+	"context"
 	"fmt"
 	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
 	"github.com/olympus-go/eris/utils"
-	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
+	"log/slog"
 	"math/rand"
-	"strings"
-	"sync"
 	"time"
 )
 
@@ -18,11 +17,28 @@ var (
 	scissorsValue = "scissors"
 )
 
+// rpsChallengeTimeout is how long a challenged user has to accept or decline.
+const rpsChallengeTimeout = 30 * time.Second
+
+// rpsGameTTL is how long an accepted game is kept around while nobody makes a move.
+const rpsGameTTL = 10 * time.Minute
+
+// rpsTimeout is the payload of a challenge timeout job. It holds everything needed to clean up after the challenge,
+// since the game itself is gone if the bot restarted in the meantime.
+type rpsTimeout struct {
+	GameId           string
+	ChannelId        string
+	MessageId        string
+	ApplicationId    string
+	InteractionToken string
+}
+
 type rpsGame struct {
 	Challenger         rpsUser
 	Challenged         rpsUser
 	Id                 string
 	ChallengeChannelId string
+	session            *discordgo.Session
 }
 
 type rpsUser struct {
@@ -32,20 +48,36 @@ type rpsUser struct {
 	Selection        string
 }
 
+// GameFinished is published on the bot's event bus whenever a game ends. Winner and Loser are user ids, and hold the
+// two players in no particular order if the game was a draw.
+type GameFinished struct {
+	Game   string
+	Winner string
+	Loser  string
+	Draw   bool
+}
+
+// rpsOptions are the options of /rps.
+type rpsOptions struct {
+	User    *discordgo.User `option:"user" description:"Challenges the specified user" required:"true"`
+	Message string          `option:"message" description:"Optional message to send with the challenge"`
+}
+
 type RpsPlugin struct {
-	activeGames map[string]*rpsGame
-	gameLock    sync.RWMutex
-	winLock     sync.Mutex
-	logger      zerolog.Logger
+	bot    *eris.Bot
+	games  *eris.SessionStore[string, *rpsGame]
+	logger *slog.Logger
 }
 
-func Rps(logger zerolog.Logger) *RpsPlugin {
+func Rps(logger *slog.Logger) *RpsPlugin {
 	rand.Seed(time.Now().UnixNano())
 
-	return &RpsPlugin{
-		activeGames: make(map[string]*rpsGame),
-		logger:      logger.With().Str("plugin", "rps").Logger(),
+	r := &RpsPlugin{
+		logger: logger.With(slog.String("plugin", "rps")),
 	}
+	r.games = eris.NewSessionStore[string, *rpsGame](rpsGameTTL, r.expire)
+
+	return r
 }
 
 func (r *RpsPlugin) Name() string {
@@ -56,285 +88,394 @@ func (r *RpsPlugin) Description() string {
 	return "Enables challenging your friends to rock paper scissors matches"
 }
 
+// Init keeps a reference to the bot, so that finished games can be published on its event bus.
+func (r *RpsPlugin) Init(_ context.Context, bot *eris.Bot) error {
+	r.bot = bot
+
+	return nil
+}
+
 func (r *RpsPlugin) Handlers() map[string]any {
-	handlers := make(map[string]any)
-
-	handlers["rps_handler"] = func(session *discordgo.Session, i *discordgo.InteractionCreate) {
-		switch i.Type {
-		case discordgo.InteractionApplicationCommand:
-			applicationCommandData := i.ApplicationCommandData()
-			if applicationCommandData.Name != "rps" {
-				return
-			}
-
-			r.logger.Debug().Str("user_id", utils.GetInteractionUserId(i.Interaction)).
-				Interface("command", applicationCommandData).Msg("user invoked slash command")
-
-			challenger := utils.GetInteractionUserId(i.Interaction)
-			challenged, ok := applicationCommandData.Options[0].Value.(string)
-			if !ok {
-				r.logger.Error().Msgf("expected value to be string, instead got %T", applicationCommandData.Options[0].Value)
-				utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
-					Message("Something went wrong.").SendWithLog(r.logger)
-				return
-			}
-
-			// Make sure the Challenger didn't challenge themselves
-			if challenged == challenger {
-				utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
-					Message("You can't challenge yourself.").SendWithLog(r.logger)
-				return
-			}
-
-			game := newRpsGame(challenger, challenged)
-
-			if _, ok = r.getGame(game.Id); ok {
-				utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
-					Message("Finish your current match first!").SendWithLog(r.logger)
-				return
-			}
-
-			// Set the channelId if the interaction was created in a guild
-			if i.Interaction.GuildID != "" {
-				game.ChallengeChannelId = i.Interaction.ChannelID
-			}
-
-			r.setGame(game)
-			r.logger.Debug().Interface("game", game).Msg("game created")
-
-			// If the Challenged user is the bot running this
-			if challenged == session.State.User.ID {
-				r.logger.Debug().Interface("game", game).Msg("bot accepted the challenge")
-
-				utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
-					Message("I'll DM you.").SendWithLog(r.logger)
-
-				var err error
-				game.Challenger.promptMessage, err = game.sendMessage(session, game.Challenger, game.generatePrompt(game.Id, true))
-				if err != nil {
-					r.logger.Error().Err(err).Interface("game", game).Str("user_id", game.Challenger.Id).
-						Msg("failed to send prompt to user")
-					// TODO add a follow up here informing the user things went wrong
-					r.deleteGame(game.Id)
-					return
-				}
-				r.logger.Debug().Interface("game", game).Str("user_id", game.Challenger.Id).Msg("prompt sent to user")
-
-				move := r.generateMove()
-				game.Challenged.Selection = move
-				r.setGame(game)
-				r.logger.Debug().Interface("game", game).Str("Selection", move).Msg("bot made a Selection")
-			} else {
-				message := ""
-				if len(applicationCommandData.Options) > 1 {
-					message, _ = applicationCommandData.Options[1].Value.(string)
-				}
-
-				var err error
-				challengeMessage := game.generateChallenge(game.Id, game.Challenger.Id, message, true)
-				game.Challenged.challengeMessage, err = game.sendMessage(session, game.Challenged, challengeMessage)
-				if err != nil {
-					r.logger.Error().Err(err).Interface("game", game).Str("user_id", game.Challenged.Id).
-						Msg("failed to send challenge to user")
-					// TODO add a follow up here informing the user things went wrong
-					r.deleteGame(game.Id)
-					return
-				}
-
-				utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
-					Message("Challenge issued.").SendWithLog(r.logger)
-				r.logger.Debug().Interface("game", game).Str("user_id", game.Challenged.Id).
-					Msg("challenge sent to user")
-
-				// TODO add timeouts back mebe
-				//go func() {
-				//	time.Sleep(30 * time.Second)
-				//	if _, ok := r.activeGames[gameId]; ok {
-				//		_ = session.ChannelMessageDelete(r.activeGames[gameId].challengeeChallenge.ChannelID, r.activeGames[gameId].challengeeChallenge.ID)
-				//		_, _ = session.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-				//			Content: "Challenge timed out.",
-				//			Flags:   uint64(discordgo.MessageFlagsEphemeral),
-				//		})
-				//		r.logger.Debug().Str("game_id", gameId).Msg("challenge timed out")
-				//		delete(r.activeGames, gameId)
-				//	}
-				//}()
-			}
-		case discordgo.InteractionMessageComponent:
-			messageComponentData := i.MessageComponentData()
-
-			if strings.HasPrefix(messageComponentData.CustomID, "rps_challenge") {
-				idSlice := strings.Split(messageComponentData.CustomID, "_")
-				if len(idSlice) != 4 {
-					r.logger.Error().Str("Id", messageComponentData.CustomID).Msg("button Id failed to split as expected")
-					utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
-						Message("Something went wrong.").SendWithLog(r.logger)
-					return
-				}
-
-				// Gather necessary info from interaction
-				gameId := idSlice[3]
-				responseSelection := idSlice[2]
-
-				// Check if the game still exists
-				game, ok := r.getGame(gameId)
-				if !ok {
-					utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
-						Message("Game no longer exists.").SendWithLog(r.logger)
-					return
-				}
-
-				switch responseSelection {
-				case "accept":
-					r.logger.Debug().Interface("game", game).Str("user_id", game.Challenged.Id).
-						Msg("challenge accepted by user")
-
-					// Send the prompt for the Challenged user
-					var err error
-					game.Challenged.promptMessage, err = game.sendMessage(session, game.Challenged, game.generatePrompt(game.Id, true))
-					if err != nil {
-						r.logger.Error().Err(err).Interface("game", game).Str("user_id", game.Challenged.Id).
-							Msg("failed to send prompt to user")
-						// TODO add a follow up here informing the user things went wrong. Might also need to inform Challenger
-						r.deleteGame(game.Id)
-						return
-					}
-
-					r.logger.Debug().Interface("game", game).Str("user_id", game.Challenged.Id).
-						Msg("prompt sent to user")
-
-					// Send the prompt for the Challenger user
-					game.Challenger.promptMessage, err = game.sendMessage(session, game.Challenger, game.generatePrompt(game.Id, true))
-					if err != nil {
-						r.logger.Error().Err(err).Interface("game", game).Str("user_id", game.Challenger.Id).
-							Msg("failed to send prompt to user")
-						// TODO add a follow up here informing the user things went wrong. Might also need to inform Challenger
-						r.deleteGame(game.Id)
-						return
-					}
-
-					r.logger.Debug().Interface("game", game).Str("user_id", game.Challenger.Id).
-						Msg("prompt sent to user")
-
-					if session == nil {
-						r.logger.Error().Msg("session is nil")
-					}
-					_ = session.ChannelMessageDelete(game.Challenged.challengeMessage.ChannelID, game.Challenged.challengeMessage.ID)
-				case "decline":
-					r.logger.Debug().Interface("game", game).Str("user_id", game.Challenger.Id).
-						Msg("challenge declined by user")
-
-					if _, err := game.sendMessage(session, game.Challenger, game.generateDecline(game.Challenged.Id)); err != nil {
-						r.logger.Error().Err(err).Interface("game", game).Str("user_id", game.Challenger.Id).
-							Msg("failed to send decline to user")
-						// TODO add a follow up here informing the user things went wrong. Might also need to inform Challenger
-						r.deleteGame(game.Id)
-						return
-					}
-
-					r.logger.Debug().Interface("game", game).Str("user_id", game.Challenger.Id).
-						Msg("decline notice sent to user")
-
-					r.deleteGame(game.Id)
-				default:
-					r.logger.Error().Interface("game", game).Str("value", responseSelection).
-						Msg("challenge response receive unexpected value")
-					utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
-						Message("Something went wrong.").SendWithLog(r.logger)
-					r.deleteGame(game.Id)
-					return
-				}
-
-				//
-			} else if strings.HasPrefix(messageComponentData.CustomID, "rps_move") {
-				idSlice := strings.Split(messageComponentData.CustomID, "_")
-				if len(idSlice) != 4 {
-					r.logger.Error().Str("Id", messageComponentData.CustomID).Msg("button Id failed to split as expected")
-					utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
-						Message("Something went wrong.").SendWithLog(r.logger)
-					return
-				}
-
-				// Gather necessary info from interaction
-				gameId := idSlice[3]
-				moveSelection := idSlice[2]
-				userId := utils.GetInteractionUserId(i.Interaction)
-
-				// Check if the game still exists
-				game, ok := r.getGame(gameId)
-				if !ok {
-					utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
-						Message("Game no longer exists.").SendWithLog(r.logger)
-					return
-				}
-
-				// Store interaction input in active game
-				if userId == game.Challenger.Id {
-					game.Challenger.Selection = moveSelection
-					r.setGame(game)
-
-					// Update the prompt and remove the buttons
-					messageEdit := discordgo.NewMessageEdit(game.Challenger.promptMessage.ChannelID, game.Challenger.promptMessage.ID)
-					content := fmt.Sprintf("You selected :%s:.", moveSelection)
-					messageEdit.Content = &content
-					messageEdit.Components = []discordgo.MessageComponent{}
-					_, _ = session.ChannelMessageEditComplex(messageEdit)
-
-					r.logger.Debug().Interface("game", game).Str("user_id", game.Challenger.Id).
-						Str("Selection", moveSelection).Msg("user made a Selection")
-				} else if userId == game.Challenged.Id {
-					game.Challenged.Selection = moveSelection
-					r.setGame(game)
-
-					// Update the prompt and remove the buttons
-					messageEdit := discordgo.NewMessageEdit(game.Challenged.promptMessage.ChannelID, game.Challenged.promptMessage.ID)
-					content := fmt.Sprintf("You selected :%s:.", moveSelection)
-					messageEdit.Content = &content
-					messageEdit.Components = []discordgo.MessageComponent{}
-					_, _ = session.ChannelMessageEditComplex(messageEdit)
-
-					r.logger.Debug().Interface("game", game).Str("user_id", game.Challenged.Id).
-						Str("Selection", moveSelection).Msg("user made a Selection")
-				} else {
-					log.Error().Str("gameId", gameId).Str("userId", userId).Msg("user interacted with button not associated with their game")
-					utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
-						Message("Something went wrong. This isn't your game.").SendWithLog(r.logger)
-					return
-				}
-
-				// Send a successful response to the interaction
-				utils.InteractionResponse(session, i.Interaction).Type(discordgo.InteractionResponseDeferredMessageUpdate).
-					SendWithLog(r.logger)
-
-				r.winCheck(session, game)
-			}
+	return nil
+}
+
+func (r *RpsPlugin) Routes() map[string]eris.HandlerFunc {
+	routes := make(map[string]eris.HandlerFunc)
+
+	routes["rps"] = r.challenge
+
+	return routes
+}
+
+func (r *RpsPlugin) challenge(req *eris.Request) error {
+	session, i := req.Session, req.Interaction
+
+	challenger := utils.GetInteractionUserId(i.Interaction)
+	var options rpsOptions
+	if err := req.Decode(&options); err != nil || options.User == nil {
+		r.logger.Error("failed to decode the challenge options",
+			slog.Any("error", err),
+		)
+		utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
+			Message("Something went wrong.").SendWithLog(r.logger)
+		return nil
+	}
+	challenged := options.User.ID
+
+	// Make sure the Challenger didn't challenge themselves
+	if challenged == challenger {
+		utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
+			Message("You can't challenge yourself.").SendWithLog(r.logger)
+		return nil
+	}
+
+	game := newRpsGame(challenger, challenged)
+	game.session = session
+
+	// Hold the game's lock until it's stored, so that two challenges between the same users can't both pass the check
+	unlock := r.games.Lock(game.Id)
+	defer unlock()
+
+	if _, ok := r.games.Get(game.Id); ok {
+		utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
+			Message("Finish your current match first!").SendWithLog(r.logger)
+		return nil
+	}
+
+	// Set the channelId if the interaction was created in a guild, preferring the configured results channel
+	if i.Interaction.GuildID != "" {
+		game.ChallengeChannelId = i.Interaction.ChannelID
+		if channelId, _ := req.Setting("results_channel").(string); channelId != "" {
+			game.ChallengeChannelId = channelId
 		}
 	}
 
-	return handlers
+	r.games.Set(game.Id, game)
+	r.logger.Debug("game created",
+		slog.Any("game", game),
+	)
+
+	// If the Challenged user is the bot running this
+	if challenged == session.State.User.ID {
+		r.logger.Debug("bot accepted the challenge",
+			slog.Any("game", game),
+		)
+
+		utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
+			Message("I'll DM you.").SendWithLog(r.logger)
+
+		var err error
+		game.Challenger.promptMessage, err = game.sendMessage(session, game.Challenger, game.generatePrompt(game.Id, true))
+		if err != nil {
+			r.logger.Error("failed to send prompt to user",
+				slog.String("error", err.Error()),
+				slog.Any("game", game),
+				slog.String("user_id", game.Challenger.Id),
+			)
+			// TODO add a follow up here informing the user things went wrong
+			r.games.Delete(game.Id)
+			return nil
+		}
+		r.logger.Debug("prompt sent to user",
+			slog.Any("game", game),
+			slog.String("user_id", game.Challenger.Id),
+		)
+
+		move := r.generateMove()
+		game.Challenged.Selection = move
+		r.games.Set(game.Id, game)
+		r.logger.Debug("bot made a Selection",
+			slog.Any("game", game),
+			slog.String("Selection", move),
+		)
+	} else {
+		var err error
+		challengeMessage := game.generateChallenge(game.Id, game.Challenger.Id, options.Message, true)
+		game.Challenged.challengeMessage, err = game.sendMessage(session, game.Challenged, challengeMessage)
+		if err != nil {
+			r.logger.Error("failed to send challenge to user",
+				slog.String("error", err.Error()),
+				slog.Any("game", game),
+				slog.String("user_id", game.Challenged.Id),
+			)
+			// TODO add a follow up here informing the user things went wrong
+			r.games.Delete(game.Id)
+			return nil
+		}
+
+		utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
+			Message("Challenge issued.").SendWithLog(r.logger)
+		r.logger.Debug("challenge sent to user",
+			slog.Any("game", game),
+			slog.String("user_id", game.Challenged.Id),
+		)
+
+		err = r.bot.Schedule(r.Name(), eris.Job{
+			Key:  "timeout_" + game.Id,
+			Task: "challenge_timeout",
+			Payload: rpsTimeout{
+				GameId:           game.Id,
+				ChannelId:        game.Challenged.challengeMessage.ChannelID,
+				MessageId:        game.Challenged.challengeMessage.ID,
+				ApplicationId:    i.Interaction.AppID,
+				InteractionToken: i.Interaction.Token,
+			},
+			At:      time.Now().Add(rpsChallengeTimeout),
+			Persist: true,
+		})
+		if err != nil {
+			r.logger.Error("failed to schedule challenge timeout",
+				slog.String("error", err.Error()),
+				slog.Any("game", game),
+			)
+		}
+	}
+
+	return nil
 }
 
-func (r *RpsPlugin) Commands() map[string]*discordgo.ApplicationCommand {
-	commands := make(map[string]*discordgo.ApplicationCommand)
+func (r *RpsPlugin) Components() map[string]eris.HandlerFunc {
+	components := make(map[string]eris.HandlerFunc)
 
-	commands["rps_cmd"] = &discordgo.ApplicationCommand{
-		Name:        "rps",
-		Description: "Challenge a user to rock paper scissors",
-		Options: []*discordgo.ApplicationCommandOption{
-			{
-				Name:        "user",
-				Description: "Challenges the specified user",
-				Type:        discordgo.ApplicationCommandOptionUser,
-				Required:    true,
-			},
-			{
-				Name:        "message",
-				Description: "Optional message to send with the challenge",
-				Type:        discordgo.ApplicationCommandOptionString,
-				Required:    false,
-			},
+	components["rps_challenge_{response}_{game}"] = r.respond
+	components["rps_move_{move}_{game}"] = r.move
+
+	return components
+}
+
+func (r *RpsPlugin) respond(req *eris.Request) error {
+	session, i := req.Session, req.Interaction
+
+	// Gather necessary info from interaction
+	gameId := req.Param("game")
+	responseSelection := req.Param("response")
+
+	unlock := r.games.Lock(gameId)
+	defer unlock()
+
+	// Check if the game still exists
+	game, ok := r.games.Get(gameId)
+	if !ok {
+		utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
+			Message("Game no longer exists.").SendWithLog(r.logger)
+		return nil
+	}
+
+	// The challenge was answered in time
+	r.bot.Cancel(r.Name(), "timeout_"+game.Id)
+
+	switch responseSelection {
+	case "accept":
+		r.logger.Debug("challenge accepted by user",
+			slog.Any("game", game),
+			slog.String("user_id", game.Challenged.Id),
+		)
+
+		// Send the prompt for the Challenged user
+		var err error
+		game.Challenged.promptMessage, err = game.sendMessage(session, game.Challenged, game.generatePrompt(game.Id, true))
+		if err != nil {
+			r.logger.Error("failed to send prompt to user",
+				slog.String("error", err.Error()),
+				slog.Any("game", game),
+				slog.String("user_id", game.Challenged.Id),
+			)
+			// TODO add a follow up here informing the user things went wrong. Might also need to inform Challenger
+			r.games.Delete(game.Id)
+			return nil
+		}
+
+		r.logger.Debug("prompt sent to user",
+			slog.Any("game", game),
+			slog.String("user_id", game.Challenged.Id),
+		)
+
+		// Send the prompt for the Challenger user
+		game.Challenger.promptMessage, err = game.sendMessage(session, game.Challenger, game.generatePrompt(game.Id, true))
+		if err != nil {
+			r.logger.Error("failed to send prompt to user",
+				slog.String("error", err.Error()),
+				slog.Any("game", game),
+				slog.String("user_id", game.Challenger.Id),
+			)
+			// TODO add a follow up here informing the user things went wrong. Might also need to inform Challenger
+			r.games.Delete(game.Id)
+			return nil
+		}
+
+		r.logger.Debug("prompt sent to user",
+			slog.Any("game", game),
+			slog.String("user_id", game.Challenger.Id),
+		)
+
+		if session == nil {
+			r.logger.Error("session is nil")
+		}
+		_ = session.ChannelMessageDelete(game.Challenged.challengeMessage.ChannelID, game.Challenged.challengeMessage.ID)
+	case "decline":
+		r.logger.Debug("challenge declined by user",
+			slog.Any("game", game),
+			slog.String("user_id", game.Challenger.Id),
+		)
+
+		if _, err := game.sendMessage(session, game.Challenger, game.generateDecline(game.Challenged.Id)); err != nil {
+			r.logger.Error("failed to send decline to user",
+				slog.String("error", err.Error()),
+				slog.Any("game", game),
+				slog.String("user_id", game.Challenger.Id),
+			)
+			// TODO add a follow up here informing the user things went wrong. Might also need to inform Challenger
+			r.games.Delete(game.Id)
+			return nil
+		}
+
+		r.logger.Debug("decline notice sent to user",
+			slog.Any("game", game),
+			slog.String("user_id", game.Challenger.Id),
+		)
+
+		r.games.Delete(game.Id)
+	default:
+		r.logger.Error("challenge response receive unexpected value",
+			slog.Any("game", game),
+			slog.String("value", responseSelection),
+		)
+		utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
+			Message("Something went wrong.").SendWithLog(r.logger)
+		r.games.Delete(game.Id)
+		return nil
+	}
+
+	return nil
+}
+
+func (r *RpsPlugin) move(req *eris.Request) error {
+	session, i := req.Session, req.Interaction
+
+	// Gather necessary info from interaction
+	gameId := req.Param("game")
+	moveSelection := req.Param("move")
+	userId := utils.GetInteractionUserId(i.Interaction)
+
+	// Both players may move at once, so the game is locked until the win check is done
+	unlock := r.games.Lock(gameId)
+	defer unlock()
+
+	// Check if the game still exists
+	game, ok := r.games.Get(gameId)
+	if !ok {
+		utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
+			Message("Game no longer exists.").SendWithLog(r.logger)
+		return nil
+	}
+
+	// Store interaction input in active game
+	if userId == game.Challenger.Id {
+		game.Challenger.Selection = moveSelection
+		r.games.Set(game.Id, game)
+
+		// Update the prompt and remove the buttons
+		messageEdit := discordgo.NewMessageEdit(game.Challenger.promptMessage.ChannelID, game.Challenger.promptMessage.ID)
+		content := fmt.Sprintf("You selected :%s:.", moveSelection)
+		messageEdit.Content = &content
+		messageEdit.Components = []discordgo.MessageComponent{}
+		_, _ = session.ChannelMessageEditComplex(messageEdit)
+
+		r.logger.Debug("user made a Selection",
+			slog.Any("game", game),
+			slog.String("user_id", game.Challenger.Id),
+			slog.String("Selection", moveSelection),
+		)
+	} else if userId == game.Challenged.Id {
+		game.Challenged.Selection = moveSelection
+		r.games.Set(game.Id, game)
+
+		// Update the prompt and remove the buttons
+		messageEdit := discordgo.NewMessageEdit(game.Challenged.promptMessage.ChannelID, game.Challenged.promptMessage.ID)
+		content := fmt.Sprintf("You selected :%s:.", moveSelection)
+		messageEdit.Content = &content
+		messageEdit.Components = []discordgo.MessageComponent{}
+		_, _ = session.ChannelMessageEditComplex(messageEdit)
+
+		r.logger.Debug("user made a Selection",
+			slog.Any("game", game),
+			slog.String("user_id", game.Challenged.Id),
+			slog.String("Selection", moveSelection),
+		)
+	} else {
+		r.logger.Error("user interacted with button not associated with their game",
+			slog.String("gameId", gameId),
+			slog.String("userId", userId),
+		)
+		utils.InteractionResponse(session, i.Interaction).Flags(discordgo.MessageFlagsEphemeral).
+			Message("Something went wrong. This isn't your game.").SendWithLog(r.logger)
+		return nil
+	}
+
+	// Send a successful response to the interaction
+	utils.InteractionResponse(session, i.Interaction).Type(discordgo.InteractionResponseDeferredMessageUpdate).
+		SendWithLog(r.logger)
+
+	r.winCheck(session, game)
+
+	return nil
+}
+
+func (r *RpsPlugin) Tasks() map[string]eris.TaskFunc {
+	tasks := make(map[string]eris.TaskFunc)
+
+	tasks["challenge_timeout"] = r.timeout
+
+	return tasks
+}
+
+// timeout withdraws a challenge that wasn't answered in time.
+func (r *RpsPlugin) timeout(_ context.Context, session *discordgo.Session, job eris.Job) error {
+	var timeout rpsTimeout
+	if err := job.Decode(&timeout); err != nil {
+		return err
+	}
+
+	unlock := r.games.Lock(timeout.GameId)
+	defer unlock()
+
+	// The challenge may have been accepted while the job was waiting for the lock
+	if game, ok := r.games.Peek(timeout.GameId); ok && game.Challenged.promptMessage != nil {
+		return nil
+	}
+
+	_ = session.ChannelMessageDelete(timeout.ChannelId, timeout.MessageId)
+	_, _ = session.FollowupMessageCreate(&discordgo.Interaction{
+		AppID: timeout.ApplicationId,
+		Token: timeout.InteractionToken,
+	}, true, &discordgo.WebhookParams{
+		Content: "Challenge timed out.",
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+
+	r.games.Delete(timeout.GameId)
+	r.logger.Debug("challenge timed out",
+		slog.String("game_id", timeout.GameId),
+	)
+
+	return nil
+}
+
+func (r *RpsPlugin) Settings() []eris.Setting {
+	return []eris.Setting{
+		{
+			Name:        "results_channel",
+			Description: "Channel match results are posted in, instead of the channel the challenge was issued in",
+			Type:        eris.SettingChannel,
+			Default:     "",
 		},
 	}
+}
+
+func (r *RpsPlugin) Commands() map[string]*discordgo.ApplicationCommand {
+	commands := make(map[string]*discordgo.ApplicationCommand)
+
+	commands["rps_cmd"] = eris.MustCommandFromStruct("rps", "Challenge a user to rock paper scissors", rpsOptions{})
 
 	return commands
 }
@@ -343,26 +484,32 @@ func (r *RpsPlugin) Intents() []discordgo.Intent {
 	return nil
 }
 
-func (r *RpsPlugin) getGame(id string) (*rpsGame, bool) {
-	r.gameLock.RLock()
-	defer r.gameLock.RUnlock()
+// Close drops any games still in progress.
+func (r *RpsPlugin) Close(_ context.Context) error {
+	r.games.Clear()
 
-	game, ok := r.activeGames[id]
-	return game, ok
+	return nil
 }
 
-func (r *RpsPlugin) setGame(game *rpsGame) {
-	r.gameLock.Lock()
-	r.activeGames[game.Id] = game
-	r.gameLock.Unlock()
-}
+// expire cleans up the messages of a game that was abandoned.
+func (r *RpsPlugin) expire(gameId string, game *rpsGame) {
+	if game.session == nil {
+		return
+	}
 
-func (r *RpsPlugin) deleteGame(gameId string) {
-	r.gameLock.Lock()
-	if _, ok := r.activeGames[gameId]; ok {
-		delete(r.activeGames, gameId)
+	for _, message := range []*discordgo.Message{
+		game.Challenged.challengeMessage,
+		game.Challenger.promptMessage,
+		game.Challenged.promptMessage,
+	} {
+		if message != nil {
+			_ = game.session.ChannelMessageDelete(message.ChannelID, message.ID)
+		}
 	}
-	r.gameLock.Unlock()
+
+	r.logger.Debug("game expired",
+		slog.String("game_id", gameId),
+	)
 }
 
 func (r *RpsPlugin) generateMove() string {
@@ -406,16 +553,14 @@ func (r *RpsPlugin) moveCmp(m1, m2 string) int {
 	return 0
 }
 
+// winCheck reports the result of the game once both players made their move. The game must be locked.
 func (r *RpsPlugin) winCheck(session *discordgo.Session, game *rpsGame) {
 	if session == nil || game == nil {
 		return
 	}
 
-	r.winLock.Lock()
-	defer r.winLock.Unlock()
-
 	// Game already concluded
-	if _, ok := r.getGame(game.Id); !ok {
+	if _, ok := r.games.Peek(game.Id); !ok {
 		return
 	}
 
@@ -427,13 +572,17 @@ func (r *RpsPlugin) winCheck(session *discordgo.Session, game *rpsGame) {
 	winnerMessage := fmt.Sprintf("<@%s> :%s:  :vs:  :%s: <@%s>\n", game.Challenger.Id, game.Challenger.Selection,
 		game.Challenged.Selection, game.Challenged.Id)
 
+	result := GameFinished{Game: r.Name(), Winner: game.Challenger.Id, Loser: game.Challenged.Id}
+
 	switch r.moveCmp(game.Challenger.Selection, game.Challenged.Selection) {
 	case -1:
 		winnerMessage += fmt.Sprintf("<@%s> wins!", game.Challenger.Id)
 	case 0:
 		winnerMessage += fmt.Sprintf("It's a tie!")
+		result.Draw = true
 	case 1:
 		winnerMessage += fmt.Sprintf("<@%s> wins!", game.Challenged.Id)
+		result.Winner, result.Loser = game.Challenged.Id, game.Challenger.Id
 	}
 
 	// If the game was launched in a channel, report the results back in the channel. Otherwise DM both
@@ -459,7 +608,11 @@ func (r *RpsPlugin) winCheck(session *discordgo.Session, game *rpsGame) {
 	}
 
 	// Close out the game
-	r.deleteGame(game.Id)
+	r.games.Delete(game.Id)
+
+	if r.bot != nil {
+		eris.Publish(r.bot, result)
+	}
 }
 
 func newRpsGame(challengerId string, challengedId string) *rpsGame {
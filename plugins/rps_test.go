@@ -0,0 +1,182 @@
+package plugins_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+	"github.com/olympus-go/eris/plugins"
+)
+
+const (
+	challengerId = "500"
+	challengedId = "600"
+	rpsGameId    = challengerId + "vs" + challengedId
+)
+
+// challenge issues a challenge from the challenger to the user and returns the response to it.
+func challenge(t *testing.T, server *eristest.Server, userId string) *discordgo.InteractionResponse {
+	t.Helper()
+
+	interaction := server.InteractionCreate(eristest.SlashCommand(challengerId, "rps",
+		eristest.Option("user", discordgo.ApplicationCommandOptionUser, userId)))
+
+	return server.WaitForResponse(interaction.ID)
+}
+
+// containsMessage returns whether any of the messages contains the text.
+func containsMessage(messages []*discordgo.Message, text string) bool {
+	for _, message := range messages {
+		if strings.Contains(message.Content, text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestRpsGame(t *testing.T) {
+	server, bot := newPluginBot(t, plugins.Rps(discardLogger()))
+
+	finished := make(chan plugins.GameFinished, 1)
+	eris.Subscribe(bot, "", func(event plugins.GameFinished) {
+		finished <- event
+	})
+
+	response := challenge(t, server, challengedId)
+	if response.Data.Content != "Challenge issued." || response.Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+		t.Fatalf("challenge response = %+v, want an ephemeral %q", response.Data, "Challenge issued.")
+	}
+
+	challengeMessage := server.WaitForDirectMessages(challengedId, 1)[0]
+	if !strings.Contains(challengeMessage.Content, "<@"+challengerId+"> Challenged you") {
+		t.Errorf("challenge message = %q, want it to name the challenger", challengeMessage.Content)
+	}
+
+	server.InteractionCreate(eristest.Component(challengedId, "rps_challenge_accept_"+rpsGameId))
+
+	// Accepting replaces the challenge with a prompt and prompts the challenger as well.
+	server.WaitForDirectMessages(challengerId, 1)
+	prompted := server.WaitFor(func() bool {
+		messages := server.DirectMessages(challengedId)
+		return len(messages) == 1 && messages[0].ID != challengeMessage.ID
+	})
+	if !prompted {
+		t.Fatalf("challenged messages = %+v, want only the prompt", server.DirectMessages(challengedId))
+	}
+
+	for _, move := range []struct {
+		userId string
+		move   string
+	}{
+		{challengerId, "rock"},
+		{challengedId, "scissors"},
+	} {
+		interaction := server.InteractionCreate(eristest.Component(move.userId, "rps_move_"+move.move+"_"+rpsGameId))
+		if response := server.WaitForResponse(interaction.ID); response.Type != discordgo.InteractionResponseDeferredMessageUpdate {
+			t.Errorf("move response type = %d, want a deferred update", response.Type)
+		}
+	}
+
+	result := "<@" + challengerId + "> wins!"
+	reported := server.WaitFor(func() bool {
+		return containsMessage(server.DirectMessages(challengerId), result) &&
+			containsMessage(server.DirectMessages(challengedId), result)
+	})
+	if !reported {
+		t.Fatalf("result %q wasn't sent to both players", result)
+	}
+
+	select {
+	case event := <-finished:
+		want := plugins.GameFinished{Game: "Rock Paper Scissors", Winner: challengerId, Loser: challengedId}
+		if event != want {
+			t.Errorf("published %+v, want %+v", event, want)
+		}
+	case <-time.After(server.Timeout):
+		t.Error("finished game wasn't published")
+	}
+
+	// The game is over, so its buttons no longer do anything.
+	interaction := server.InteractionCreate(eristest.Component(challengerId, "rps_move_rock_"+rpsGameId))
+	if response := server.WaitForResponse(interaction.ID); response.Data.Content != "Game no longer exists." {
+		t.Errorf("move after the game response = %q, want %q", response.Data.Content, "Game no longer exists.")
+	}
+}
+
+func TestRpsDecline(t *testing.T) {
+	server, _ := newPluginBot(t, plugins.Rps(discardLogger()))
+
+	challenge(t, server, challengedId)
+	server.WaitForDirectMessages(challengedId, 1)
+
+	server.InteractionCreate(eristest.Component(challengedId, "rps_challenge_decline_"+rpsGameId))
+
+	decline := server.WaitForDirectMessages(challengerId, 1)[0]
+	if !strings.Contains(decline.Content, "<@"+challengedId+">") {
+		t.Errorf("decline message = %q, want it to name the challenged user", decline.Content)
+	}
+
+	// Declining ends the game, so it can be issued again.
+	if response := challenge(t, server, challengedId); response.Data.Content != "Challenge issued." {
+		t.Errorf("second challenge response = %q, want %q", response.Data.Content, "Challenge issued.")
+	}
+}
+
+func TestRpsChallengeRejected(t *testing.T) {
+	server, _ := newPluginBot(t, plugins.Rps(discardLogger()))
+
+	if response := challenge(t, server, challengerId); response.Data.Content != "You can't challenge yourself." {
+		t.Errorf("self challenge response = %q, want %q", response.Data.Content, "You can't challenge yourself.")
+	}
+
+	challenge(t, server, challengedId)
+	if response := challenge(t, server, challengedId); response.Data.Content != "Finish your current match first!" {
+		t.Errorf("repeated challenge response = %q, want %q", response.Data.Content,
+			"Finish your current match first!")
+	}
+}
+
+func TestRpsChallengeBot(t *testing.T) {
+	server, _ := newPluginBot(t, plugins.Rps(discardLogger()))
+
+	if response := challenge(t, server, server.BotUserId); response.Data.Content != "I'll DM you." {
+		t.Fatalf("bot challenge response = %q, want %q", response.Data.Content, "I'll DM you.")
+	}
+
+	// The bot has moved already, so the game is decided by the challenger's move.
+	server.WaitForDirectMessages(challengerId, 1)
+	server.InteractionCreate(eristest.Component(challengerId, "rps_move_rock_"+challengerId+"vs"+server.BotUserId))
+
+	reported := server.WaitFor(func() bool {
+		messages := server.DirectMessages(challengerId)
+		return containsMessage(messages, "wins!") || containsMessage(messages, "It's a tie!")
+	})
+	if !reported {
+		t.Errorf("challenger messages = %+v, want the result", server.DirectMessages(challengerId))
+	}
+}
+
+func TestRpsConcurrentChallenges(t *testing.T) {
+	server, _ := newPluginBot(t, plugins.Rps(discardLogger()))
+
+	// Both challenges are dispatched before either is answered, so only the game's lock keeps them apart.
+	var interactions []*discordgo.Interaction
+	for i := 0; i < 2; i++ {
+		interactions = append(interactions, server.InteractionCreate(eristest.SlashCommand(challengerId, "rps",
+			eristest.Option("user", discordgo.ApplicationCommandOptionUser, challengedId))))
+	}
+
+	issued := 0
+	for _, interaction := range interactions {
+		if server.WaitForResponse(interaction.ID).Data.Content == "Challenge issued." {
+			issued++
+		}
+	}
+	if issued != 1 {
+		t.Errorf("%d challenges were issued, want 1", issued)
+	}
+}
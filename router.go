@@ -0,0 +1,263 @@
+package eris
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris/utils"
+)
+
+// HandlerFunc handles an interaction routed to it by the bot. A returned error is logged, and the user is told that
+// something went wrong if the interaction hasn't been responded to yet.
+type HandlerFunc func(r *Request) error
+
+// Router can optionally be implemented by a plugin to have slash commands routed to it by path, instead of filtering
+// every InteractionCreate in a handler. A path is the command name followed by the subcommand group and subcommand
+// (if any) separated by spaces, e.g. "plugins" or "21q start".
+type Router interface {
+	Routes() map[string]HandlerFunc
+}
+
+// Request is an interaction that has been routed to a HandlerFunc.
+type Request struct {
+	Session     *discordgo.Session
+	Interaction *discordgo.InteractionCreate
+	// Path is the route the interaction matched.
+	Path string
+	// Plugin is the name of the plugin the route belongs to.
+	Plugin string
+	// Options are the options of the invoked (sub)command, keyed by name.
+	Options map[string]*discordgo.ApplicationCommandInteractionDataOption
+	// Params are the parameters extracted from a component's CustomID, keyed by name.
+	Params map[string]string
+	// Store is the storage namespace of the plugin the route belongs to.
+	Store *Store
+
+	bot *Bot
+}
+
+// Respond returns a response builder for the request's interaction.
+func (r *Request) Respond() *utils.InteractionResponseBuilder {
+	return utils.InteractionResponse(r.Session, r.Interaction.Interaction)
+}
+
+// UserId returns the id of the user that created the interaction.
+func (r *Request) UserId() string {
+	return utils.GetInteractionUserId(r.Interaction.Interaction)
+}
+
+// Option returns the value of the named option, or nil if it wasn't supplied.
+func (r *Request) Option(name string) any {
+	if option, ok := r.Options[name]; ok {
+		return option.Value
+	}
+
+	return nil
+}
+
+// Param returns the named CustomID parameter, or an empty string if the pattern doesn't define it.
+func (r *Request) Param(name string) string {
+	return r.Params[name]
+}
+
+// IntParam returns the named CustomID parameter as an int. Parameters declared as "{name:int}" are guaranteed to
+// convert; 0 is returned for anything that doesn't.
+func (r *Request) IntParam(name string) int {
+	value, _ := strconv.Atoi(r.Params[name])
+	return value
+}
+
+type route struct {
+	plugin     string
+	permission Permission
+	handler    HandlerFunc
+}
+
+// addRoutes registers the routes of a plugin, replacing any it registered before, and fails without changing anything
+// if a path is already taken by another plugin.
+func (b *Bot) addRoutes(plugin Plugin) error {
+	router, ok := plugin.(Router)
+	if !ok {
+		return nil
+	}
+
+	routes := router.Routes()
+	middleware := pluginMiddleware(plugin)
+
+	b.routesLock.Lock()
+	defer b.routesLock.Unlock()
+
+	for path := range routes {
+		if existing, ok := b.routes[normalizePath(path)]; ok && existing.plugin != plugin.Name() {
+			return fmt.Errorf("route %q is already registered by plugin %q", path, existing.plugin)
+		}
+	}
+
+	for path, route := range b.routes {
+		if route.plugin == plugin.Name() {
+			delete(b.routes, path)
+		}
+	}
+
+	for path, handler := range routes {
+		b.routes[normalizePath(path)] = route{
+			plugin:     plugin.Name(),
+			permission: pluginPermission(plugin, path),
+			handler:    Chain(handler, middleware...),
+		}
+	}
+
+	return nil
+}
+
+// removeRoutes removes every route registered by the plugin.
+func (b *Bot) removeRoutes(name string) {
+	b.routesLock.Lock()
+	defer b.routesLock.Unlock()
+
+	for path, route := range b.routes {
+		if route.plugin == name {
+			delete(b.routes, path)
+		}
+	}
+}
+
+// setCommandOwners records which plugin declared each command name, so that commands nobody handles can be told apart
+// from commands handled by a plugin's own handlers. The names of the previous commands of the plugin are released and
+// those of the current ones are claimed at once.
+func (b *Bot) setCommandOwners(plugin string, previous, current map[string]*discordgo.ApplicationCommand) {
+	b.routesLock.Lock()
+	defer b.routesLock.Unlock()
+
+	for _, command := range previous {
+		if b.commandOwners[command.Name] == plugin {
+			delete(b.commandOwners, command.Name)
+		}
+	}
+
+	for _, command := range current {
+		b.commandOwners[command.Name] = plugin
+	}
+}
+
+// routeInteraction is registered as a handler on the session and dispatches application commands, autocompletes and
+// components to the matching route.
+func (b *Bot) routeInteraction(session *discordgo.Session, i *discordgo.InteractionCreate) {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		b.routeCommand(session, i)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		b.routeAutocomplete(session, i)
+	case discordgo.InteractionMessageComponent, discordgo.InteractionModalSubmit:
+		b.routeComponent(session, i)
+	}
+}
+
+// routeCommand dispatches an application command to the route matching its path. Commands that don't belong to any
+// loaded plugin get a default ephemeral reply.
+func (b *Bot) routeCommand(session *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	path, options := commandPath(data)
+
+	b.routesLock.RLock()
+	route, ok := b.routes[path]
+	_, owned := b.commandOwners[data.Name]
+	b.routesLock.RUnlock()
+
+	if !ok {
+		if !owned {
+			b.Logger.Debug("received unknown command", slog.String("path", path))
+			utils.InteractionResponse(session, i.Interaction).Ephemeral().
+				Message("Unknown command.").SendWithLog(b.Logger)
+		}
+		return
+	}
+
+	b.dispatch(route.plugin, route.permission, route.handler, &Request{
+		Session:     session,
+		Interaction: i,
+		Path:        path,
+		Plugin:      route.plugin,
+		Options:     options,
+	})
+}
+
+// dispatch checks that the user has the required permission and then calls a route's handler wrapped in the global
+// middleware, logging any error it returns and letting the user know something went wrong.
+func (b *Bot) dispatch(plugin string, permission Permission, handler HandlerFunc, request *Request) {
+	request.Store = b.PluginStore(plugin)
+	request.bot = b
+
+	if !b.authorize(request, permission) {
+		b.Logger.Debug("denied access to route",
+			slog.String("plugin", plugin),
+			slog.String("path", request.Path),
+			slog.String("user_id", request.UserId()),
+		)
+
+		request.Respond().Ephemeral().Message("You don't have permission to use this.").SendWithLog(b.Logger)
+		return
+	}
+
+	b.routesLock.RLock()
+	handler = Chain(handler, b.middleware...)
+	b.routesLock.RUnlock()
+
+	defer func() {
+		if value := recover(); value != nil {
+			b.handlePanic(value, plugin, slog.String("path", request.Path), request.Session, request.Interaction)
+		}
+	}()
+
+	b.metrics.interactions.WithLabelValues(plugin, request.Path).Inc()
+	start := time.Now()
+	defer func() {
+		b.metrics.duration.WithLabelValues(plugin, request.Path).Observe(time.Since(start).Seconds())
+	}()
+
+	if err := handler(request); err != nil {
+		b.recordFailure(plugin)
+		b.metrics.errors.WithLabelValues(plugin, request.Path).Inc()
+		b.Logger.Error("route handler failed",
+			slog.String("plugin", plugin),
+			slog.String("path", request.Path),
+			slog.String("error", err.Error()),
+		)
+
+		// If the handler already responded this fails, which is fine.
+		_ = request.Respond().Ephemeral().Message("Something went wrong.").Send()
+	}
+}
+
+// commandPath returns the route path of an invoked command along with the options of the innermost (sub)command.
+func commandPath(data discordgo.ApplicationCommandInteractionData) (string, map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	path := []string{data.Name}
+	options := data.Options
+
+	for len(options) > 0 {
+		option := options[0]
+		if option.Type != discordgo.ApplicationCommandOptionSubCommandGroup &&
+			option.Type != discordgo.ApplicationCommandOptionSubCommand {
+			break
+		}
+
+		path = append(path, option.Name)
+		options = option.Options
+	}
+
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, option := range options {
+		optionMap[option.Name] = option
+	}
+
+	return strings.Join(path, " "), optionMap
+}
+
+// normalizePath collapses repeated whitespace in a route path.
+func normalizePath(path string) string {
+	return strings.Join(strings.Fields(path), " ")
+}
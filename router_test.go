@@ -0,0 +1,51 @@
+package eris_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+func TestRouteCommands(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	plugin := &testPlugin{
+		name:     "Game",
+		commands: map[string]*discordgo.ApplicationCommand{"game": chatCommand("game", "Plays a game")},
+		routes: map[string]eris.HandlerFunc{
+			"game start": func(r *eris.Request) error {
+				return r.Respond().Message(fmt.Sprintf("start %v", r.Option("rounds"))).Send()
+			},
+			"game stop": func(r *eris.Request) error {
+				return r.Respond().Message("stop").Send()
+			},
+		},
+	}
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	for _, test := range []struct {
+		interaction *discordgo.Interaction
+		want        string
+	}{
+		{eristest.SlashCommand("500", "game", eristest.SubCommand("start",
+			eristest.Option("rounds", discordgo.ApplicationCommandOptionInteger, 3))), "start 3"},
+		{eristest.SlashCommand("500", "game", eristest.SubCommand("stop")), "stop"},
+		{eristest.SlashCommand("500", "missing"), "Unknown command."},
+	} {
+		name := test.interaction.ApplicationCommandData().Name
+		interaction := server.InteractionCreate(test.interaction)
+		response := server.WaitForResponse(interaction.ID)
+		if response.Data.Content != test.want {
+			t.Errorf("response to /%s = %q, want %q", name, response.Data.Content, test.want)
+		}
+		if name == "missing" && response.Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+			t.Error("reply to an unknown command isn't ephemeral")
+		}
+	}
+}
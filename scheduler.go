@@ -0,0 +1,342 @@
+package eris
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robfig/cron/v3"
+)
+
+// jobKeyPrefix is the prefix persisted jobs are stored under in their plugin's store.
+const jobKeyPrefix = "jobs/"
+
+// TaskFunc runs a scheduled job. The session is the one REST calls should be made with.
+type TaskFunc func(ctx context.Context, session *discordgo.Session, job Job) error
+
+// TaskProvider can optionally be implemented by a plugin that schedules jobs. Tasks are keyed by name, and jobs refer
+// to the task they run by that name so that persisted jobs can be picked up again after a restart.
+type TaskProvider interface {
+	Tasks() map[string]TaskFunc
+}
+
+// Job is a task scheduled on behalf of a plugin, either once at a given time or repeatedly on a cron schedule.
+type Job struct {
+	// Key identifies the job within its plugin. Scheduling a job replaces any job of the same plugin with the same key.
+	Key string `json:"key"`
+	// Task is the name of the task to run, as returned by the plugin's Tasks.
+	Task string `json:"task"`
+	// Payload is encoded as JSON when the job is scheduled. The task reads it back with Decode.
+	Payload any `json:"-"`
+	// At is when a one-shot job runs. Jobs that are due already run right away. For recurring jobs it is set to the
+	// next run.
+	At time.Time `json:"at"`
+	// Cron makes the job recurring. It is a standard five field cron expression such as "0 9 * * MON", or a descriptor
+	// such as "@hourly" or "@every 10m".
+	Cron string `json:"cron,omitempty"`
+	// Persist stores the job in the plugin's storage, so that it is scheduled again when the plugin is added after a
+	// restart. One-shot jobs that came due while the bot was down run right away, recurring jobs skip the missed runs.
+	Persist bool `json:"persist,omitempty"`
+	// Plugin is the name of the plugin the job belongs to. It is set by Schedule.
+	Plugin string `json:"plugin"`
+}
+
+// Decode decodes the job's payload into v.
+func (j Job) Decode(v any) error {
+	raw, ok := j.Payload.(json.RawMessage)
+	if !ok {
+		return fmt.Errorf("job %q has no encoded payload", j.Key)
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+// storedJob is a Job along with its encoded payload, as persisted.
+type storedJob struct {
+	Job
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// scheduledJob is a job waiting for its timer.
+type scheduledJob struct {
+	job      Job
+	schedule cron.Schedule
+	timer    *time.Timer
+}
+
+// Schedule schedules the job on behalf of the plugin, replacing any job of the plugin with the same key. The task has
+// to be one of the plugin's Tasks, and either At or Cron has to be set. Jobs are cancelled when their plugin is removed.
+func (b *Bot) Schedule(plugin string, job Job) error {
+	if job.Key == "" {
+		return errors.New("job has no key")
+	}
+
+	var schedule cron.Schedule
+	if job.Cron != "" {
+		var err error
+		if schedule, err = cron.ParseStandard(job.Cron); err != nil {
+			return fmt.Errorf("job %q has an invalid cron expression: %w", job.Key, err)
+		}
+		job.At = schedule.Next(time.Now())
+	} else if job.At.IsZero() {
+		return fmt.Errorf("job %q has neither At nor Cron set", job.Key)
+	}
+
+	if _, ok := job.Payload.(json.RawMessage); !ok && job.Payload != nil {
+		raw, err := json.Marshal(job.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode the payload of job %q: %w", job.Key, err)
+		}
+		job.Payload = json.RawMessage(raw)
+	}
+
+	job.Plugin = plugin
+
+	b.jobsLock.Lock()
+	defer b.jobsLock.Unlock()
+
+	if _, ok := b.tasks[plugin][job.Task]; !ok {
+		return fmt.Errorf("plugin %q has no task %q", plugin, job.Task)
+	}
+
+	if job.Persist {
+		if err := b.persistJob(job); err != nil {
+			return fmt.Errorf("failed to persist job %q: %w", job.Key, err)
+		}
+	}
+
+	if existing, ok := b.jobs[plugin][job.Key]; ok {
+		existing.timer.Stop()
+		// A persisted job replaced by one that isn't persisted must not be restored after a restart.
+		if !job.Persist {
+			b.forgetJob(existing.job)
+		}
+	}
+	if _, ok := b.jobs[plugin]; !ok {
+		b.jobs[plugin] = make(map[string]*scheduledJob)
+	}
+
+	// The timer can't run the job before it's stored, since runJob needs the lock held here.
+	entry := &scheduledJob{job: job, schedule: schedule}
+	entry.timer = time.AfterFunc(time.Until(job.At), func() {
+		b.runJob(entry)
+	})
+	b.jobs[plugin][job.Key] = entry
+
+	return nil
+}
+
+// Cancel cancels the plugin's job with the given key, and deletes it from storage if it was persisted. It returns
+// whether such a job was scheduled.
+func (b *Bot) Cancel(plugin string, key string) bool {
+	b.jobsLock.Lock()
+	defer b.jobsLock.Unlock()
+
+	entry, ok := b.jobs[plugin][key]
+	if !ok {
+		return false
+	}
+
+	entry.timer.Stop()
+	delete(b.jobs[plugin], key)
+	b.forgetJob(entry.job)
+
+	return true
+}
+
+// Jobs returns the scheduled jobs of the plugin, ordered by when they run next.
+func (b *Bot) Jobs(plugin string) []Job {
+	b.jobsLock.Lock()
+	defer b.jobsLock.Unlock()
+
+	jobs := make([]Job, 0, len(b.jobs[plugin]))
+	for _, entry := range b.jobs[plugin] {
+		jobs = append(jobs, entry.job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].At.Before(jobs[j].At)
+	})
+
+	return jobs
+}
+
+// runJob runs a job whose timer fired, unless it was cancelled or replaced in the meantime. Recurring jobs are
+// scheduled again before they run, and one-shot jobs are removed.
+func (b *Bot) runJob(entry *scheduledJob) {
+	job := entry.job
+
+	b.jobsLock.Lock()
+	if b.jobs[job.Plugin][job.Key] != entry {
+		b.jobsLock.Unlock()
+		return
+	}
+
+	task := b.tasks[job.Plugin][job.Task]
+
+	if entry.schedule != nil {
+		entry.job.At = entry.schedule.Next(time.Now())
+		entry.timer = time.AfterFunc(time.Until(entry.job.At), func() {
+			b.runJob(entry)
+		})
+		if job.Persist {
+			if err := b.persistJob(entry.job); err != nil {
+				b.Logger.Error("failed to persist job",
+					slog.String("plugin", job.Plugin),
+					slog.String("job", job.Key),
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+	} else {
+		delete(b.jobs[job.Plugin], job.Key)
+		b.forgetJob(job)
+	}
+	b.jobsLock.Unlock()
+
+	if task == nil {
+		b.Logger.Warn("skipping job of unknown task",
+			slog.String("plugin", job.Plugin),
+			slog.String("job", job.Key),
+			slog.String("task", job.Task),
+		)
+		return
+	}
+
+	if !b.acquire() {
+		return
+	}
+	defer b.inFlight.Done()
+
+	defer func() {
+		if value := recover(); value != nil {
+			b.handlePanic(value, job.Plugin, slog.String("task", job.Task), nil, nil)
+		}
+	}()
+
+	if err := task(context.Background(), b.shardFor(""), job); err != nil {
+		b.recordFailure(job.Plugin)
+		b.Logger.Error("scheduled task failed",
+			slog.String("plugin", job.Plugin),
+			slog.String("job", job.Key),
+			slog.String("task", job.Task),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// persistJob stores the job in its plugin's store.
+func (b *Bot) persistJob(job Job) error {
+	stored := storedJob{Job: job}
+	stored.Payload, _ = job.Payload.(json.RawMessage)
+
+	return b.PluginStore(job.Plugin).SetJSON(jobKeyPrefix+job.Key, stored)
+}
+
+// forgetJob deletes the job from its plugin's store if it was persisted.
+func (b *Bot) forgetJob(job Job) {
+	if !job.Persist {
+		return
+	}
+
+	if err := b.PluginStore(job.Plugin).Delete(jobKeyPrefix + job.Key); err != nil {
+		b.Logger.Error("failed to delete persisted job",
+			slog.String("plugin", job.Plugin),
+			slog.String("job", job.Key),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// setTasks registers the tasks of the plugin, replacing any it registered before.
+func (b *Bot) setTasks(plugin Plugin) {
+	b.jobsLock.Lock()
+	defer b.jobsLock.Unlock()
+
+	delete(b.tasks, plugin.Name())
+	if provider, ok := plugin.(TaskProvider); ok {
+		b.tasks[plugin.Name()] = provider.Tasks()
+	}
+}
+
+// restoreJobs schedules the persisted jobs of the plugin again. Jobs the plugin already scheduled under the same key,
+// for example from Init, take precedence, and the stored job is deleted if they aren't persisted themselves.
+func (b *Bot) restoreJobs(plugin string) {
+	store := b.PluginStore(plugin)
+
+	keys, err := store.Keys(jobKeyPrefix)
+	if err != nil {
+		b.Logger.Error("failed to list persisted jobs", slog.String("plugin", plugin), slog.String("error", err.Error()))
+		return
+	}
+
+	for _, key := range keys {
+		var stored storedJob
+		if err = store.GetJSON(key, &stored); err != nil {
+			b.Logger.Error("failed to load persisted job",
+				slog.String("plugin", plugin),
+				slog.String("job", key),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		b.jobsLock.Lock()
+		existing, scheduled := b.jobs[plugin][stored.Key]
+		b.jobsLock.Unlock()
+		if scheduled {
+			if !existing.job.Persist {
+				b.forgetJob(stored.Job)
+			}
+			continue
+		}
+
+		job := stored.Job
+		if stored.Payload != nil {
+			job.Payload = stored.Payload
+		}
+
+		if err = b.Schedule(plugin, job); err != nil {
+			b.Logger.Warn("failed to restore persisted job",
+				slog.String("plugin", plugin),
+				slog.String("job", stored.Key),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// rescheduleJobs schedules the jobs again on behalf of the plugin, for example to bring back jobs that were stopped.
+func (b *Bot) rescheduleJobs(plugin string, jobs []Job) {
+	for _, job := range jobs {
+		if err := b.Schedule(plugin, job); err != nil {
+			b.Logger.Warn("failed to reschedule job",
+				slog.String("plugin", plugin),
+				slog.String("job", job.Key),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// stopJobs stops the timers of the plugin's jobs and unregisters its tasks. If forget is set, persisted jobs are also
+// deleted, otherwise they're picked up again the next time the plugin is added.
+func (b *Bot) stopJobs(plugin string, forget bool) {
+	b.jobsLock.Lock()
+	defer b.jobsLock.Unlock()
+
+	for _, entry := range b.jobs[plugin] {
+		entry.timer.Stop()
+		if forget {
+			b.forgetJob(entry.job)
+		}
+	}
+
+	delete(b.jobs, plugin)
+	delete(b.tasks, plugin)
+}
@@ -0,0 +1,96 @@
+package eris_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+// taskPlugin is a lifecycle plugin that provides a task named "noop".
+type taskPlugin struct {
+	*lifecyclePlugin
+}
+
+func (p *taskPlugin) Tasks() map[string]eris.TaskFunc {
+	return map[string]eris.TaskFunc{
+		"noop": func(_ context.Context, _ *discordgo.Session, _ eris.Job) error {
+			return nil
+		},
+	}
+}
+
+func newTaskPlugin(init func(ctx context.Context, bot *eris.Bot) error) *taskPlugin {
+	return &taskPlugin{&lifecyclePlugin{testPlugin: &testPlugin{name: "Tasks"}, init: init}}
+}
+
+// storedJobs returns the keys of the jobs persisted for the plugin.
+func storedJobs(t *testing.T, bot *eris.Bot, plugin string) []string {
+	t.Helper()
+
+	keys, err := bot.PluginStore(plugin).Keys("jobs/")
+	if err != nil {
+		t.Fatalf("failed to list persisted jobs: %v", err)
+	}
+
+	return keys
+}
+
+func TestScheduleReplacePersisted(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	if err := bot.AddPlugin(newTaskPlugin(nil)); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	at := time.Now().Add(time.Hour)
+	if err := bot.Schedule("Tasks", eris.Job{Key: "reminder", Task: "noop", At: at, Persist: true}); err != nil {
+		t.Fatalf("failed to schedule persisted job: %v", err)
+	}
+	if keys := storedJobs(t, bot, "Tasks"); len(keys) != 1 {
+		t.Fatalf("expected the job to be persisted, got %v", keys)
+	}
+
+	if err := bot.Schedule("Tasks", eris.Job{Key: "reminder", Task: "noop", At: at}); err != nil {
+		t.Fatalf("failed to replace job: %v", err)
+	}
+	if keys := storedJobs(t, bot, "Tasks"); len(keys) != 0 {
+		t.Errorf("expected the replaced job to be forgotten, got %v", keys)
+	}
+
+	// Unloading keeps persisted jobs, so adding the plugin again is what a restart looks like.
+	if err := bot.UnloadPlugin("Tasks"); err != nil {
+		t.Fatalf("failed to unload plugin: %v", err)
+	}
+	if err := bot.AddPlugin(newTaskPlugin(nil)); err != nil {
+		t.Fatalf("failed to add plugin again: %v", err)
+	}
+	if jobs := bot.Jobs("Tasks"); len(jobs) != 0 {
+		t.Errorf("expected no jobs to be restored, got %v", jobs)
+	}
+}
+
+func TestAddPluginInitFailureForgetsJobs(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	plugin := newTaskPlugin(func(_ context.Context, bot *eris.Bot) error {
+		job := eris.Job{Key: "reminder", Task: "noop", At: time.Now().Add(time.Hour), Persist: true}
+		if err := bot.Schedule("Tasks", job); err != nil {
+			return err
+		}
+		return errors.New("init failed")
+	})
+	if err := bot.AddPlugin(plugin); err == nil {
+		t.Fatal("expected AddPlugin to fail")
+	}
+
+	if keys := storedJobs(t, bot, "Tasks"); len(keys) != 0 {
+		t.Errorf("expected the jobs scheduled by Init to be forgotten, got %v", keys)
+	}
+}
@@ -0,0 +1,283 @@
+package eris
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SessionStore holds the state of interactive flows, such as games, keyed by something like a user or game id. Entries
+// expire once they haven't been used for their TTL, at which point the store's expiry callback is called with them, for
+// example to delete the messages a flow left behind. It is safe for concurrent use.
+type SessionStore[K comparable, V any] struct {
+	ttl      time.Duration
+	onExpire func(key K, value V)
+	store    *Store
+	prefix   string
+
+	lock    sync.Mutex
+	entries map[K]*sessionEntry[V]
+	keys    map[K]*keyLock
+}
+
+// sessionEntry is a value along with when it expires.
+type sessionEntry[V any] struct {
+	value   V
+	ttl     time.Duration
+	expires time.Time
+	timer   *time.Timer
+}
+
+// keyLock is the lock of a single key. It's dropped once nobody holds or waits for it.
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+// sessionRecord is a persisted entry.
+type sessionRecord[K comparable, V any] struct {
+	Key     K             `json:"key"`
+	Value   V             `json:"value"`
+	TTL     time.Duration `json:"ttl"`
+	Expires time.Time     `json:"expires"`
+}
+
+// NewSessionStore returns an empty store whose entries expire after ttl without activity. onExpire may be nil. It is
+// called on a goroutine of its own.
+func NewSessionStore[K comparable, V any](ttl time.Duration, onExpire func(key K, value V)) *SessionStore[K, V] {
+	return &SessionStore[K, V]{
+		ttl:      ttl,
+		onExpire: onExpire,
+		entries:  make(map[K]*sessionEntry[V]),
+		keys:     make(map[K]*keyLock),
+	}
+}
+
+// Persist loads the entries previously persisted under prefix in store, and persists every entry from then on. It should
+// be called before the store is used. Values are stored as JSON, so only their exported fields survive, and they are
+// written whenever an entry is Set, and again whenever Get or Touch extends its expiry. Entries that expired while the
+// bot was down expire right away, so their expiry callback still runs.
+func (s *SessionStore[K, V]) Persist(store *Store, prefix string) error {
+	keys, err := store.Keys(prefix)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.store = store
+	s.prefix = prefix
+
+	for _, storeKey := range keys {
+		var record sessionRecord[K, V]
+		if err = store.GetJSON(storeKey, &record); err != nil {
+			return fmt.Errorf("failed to load session %s: %w", storeKey, err)
+		}
+
+		s.add(record.Key, record.Value, record.TTL, record.Expires)
+	}
+
+	return nil
+}
+
+// Set stores the value under the key with the store's TTL, replacing any existing entry without calling the expiry
+// callback for it.
+func (s *SessionStore[K, V]) Set(key K, value V) {
+	s.SetWithTTL(key, value, s.ttl)
+}
+
+// SetWithTTL is like Set, but the entry expires after ttl without activity instead of the store's TTL.
+func (s *SessionStore[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if existing, ok := s.entries[key]; ok {
+		existing.timer.Stop()
+	}
+
+	entry := s.add(key, value, ttl, time.Now().Add(ttl))
+	s.persist(key, entry)
+}
+
+// Get returns the value of the key and extends its expiry, since reading an entry counts as activity.
+func (s *SessionStore[K, V]) Get(key K) (V, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	s.extend(key, entry)
+
+	return entry.value, true
+}
+
+// Peek returns the value of the key without extending its expiry.
+func (s *SessionStore[K, V]) Peek(key K) (V, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	return entry.value, true
+}
+
+// Touch extends the expiry of the key without reading it, and returns whether it exists.
+func (s *SessionStore[K, V]) Touch(key K) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	entry, ok := s.entries[key]
+	if ok {
+		s.extend(key, entry)
+	}
+
+	return ok
+}
+
+// Delete removes the key without calling the expiry callback.
+func (s *SessionStore[K, V]) Delete(key K) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return
+	}
+
+	entry.timer.Stop()
+	delete(s.entries, key)
+	s.forget(key)
+}
+
+// Len returns the number of entries.
+func (s *SessionStore[K, V]) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return len(s.entries)
+}
+
+// Clear removes every entry, including persisted ones, without calling the expiry callback.
+func (s *SessionStore[K, V]) Clear() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for key, entry := range s.entries {
+		entry.timer.Stop()
+		s.forget(key)
+	}
+
+	s.entries = make(map[K]*sessionEntry[V])
+}
+
+// Lock locks the key until the returned function is called, so that handlers acting on the same session don't
+// interleave. The key doesn't have to exist. Entries don't expire while their key is locked, and the expiry callback is
+// called with the key locked, so it must not lock it again.
+func (s *SessionStore[K, V]) Lock(key K) func() {
+	s.lock.Lock()
+	l, ok := s.keys[key]
+	if !ok {
+		l = &keyLock{}
+		s.keys[key] = l
+	}
+	l.refs++
+	s.lock.Unlock()
+
+	l.Lock()
+
+	return sync.OnceFunc(func() {
+		l.Unlock()
+
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		l.refs--
+		if l.refs == 0 {
+			delete(s.keys, key)
+		}
+	})
+}
+
+// add stores a new entry and starts its timer. The lock must be held.
+func (s *SessionStore[K, V]) add(key K, value V, ttl time.Duration, expires time.Time) *sessionEntry[V] {
+	entry := &sessionEntry[V]{value: value, ttl: ttl, expires: expires}
+	entry.timer = time.AfterFunc(time.Until(expires), func() {
+		s.expire(key, entry)
+	})
+	s.entries[key] = entry
+
+	return entry
+}
+
+// expire removes the entry and calls the expiry callback, unless the entry was replaced or used in the meantime, in
+// which case its timer is started again.
+func (s *SessionStore[K, V]) expire(key K, entry *sessionEntry[V]) {
+	unlock := s.Lock(key)
+	defer unlock()
+
+	s.lock.Lock()
+	if s.entries[key] != entry {
+		s.lock.Unlock()
+		return
+	}
+
+	if remaining := time.Until(entry.expires); remaining > 0 {
+		entry.timer = time.AfterFunc(remaining, func() {
+			s.expire(key, entry)
+		})
+		s.lock.Unlock()
+		return
+	}
+
+	delete(s.entries, key)
+	s.forget(key)
+	s.lock.Unlock()
+
+	if s.onExpire != nil {
+		s.onExpire(key, entry.value)
+	}
+}
+
+// extend moves the expiry of the entry a full TTL ahead, and persists it so that the entry doesn't expire at its old
+// deadline after a restart. The lock must be held.
+func (s *SessionStore[K, V]) extend(key K, entry *sessionEntry[V]) {
+	entry.expires = time.Now().Add(entry.ttl)
+	s.persist(key, entry)
+}
+
+// persist writes the entry to the store, if the store is persisted. The lock must be held.
+func (s *SessionStore[K, V]) persist(key K, entry *sessionEntry[V]) {
+	if s.store == nil {
+		return
+	}
+
+	// Failing to persist only loses the entry across restarts, so it isn't worth failing Set over.
+	_ = s.store.SetJSON(s.storeKey(key), sessionRecord[K, V]{
+		Key:     key,
+		Value:   entry.value,
+		TTL:     entry.ttl,
+		Expires: entry.expires,
+	})
+}
+
+// forget deletes the key from the store, if the store is persisted. The lock must be held.
+func (s *SessionStore[K, V]) forget(key K) {
+	if s.store == nil {
+		return
+	}
+
+	_ = s.store.Delete(s.storeKey(key))
+}
+
+func (s *SessionStore[K, V]) storeKey(key K) string {
+	return s.prefix + fmt.Sprint(key)
+}
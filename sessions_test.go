@@ -0,0 +1,84 @@
+package eris_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+// sessionRecord mirrors the record a SessionStore persists an entry as.
+type sessionRecord struct {
+	Key     string        `json:"key"`
+	Value   int           `json:"value"`
+	TTL     time.Duration `json:"ttl"`
+	Expires time.Time     `json:"expires"`
+}
+
+func TestSessionStoreTouchPersists(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+	store := bot.PluginStore("Sessions")
+
+	// The entry was persisted by an earlier run and is about to expire.
+	deadline := time.Now().Add(time.Minute)
+	if err := store.SetJSON("sessions/game", sessionRecord{Key: "game", Value: 1, TTL: time.Hour, Expires: deadline}); err != nil {
+		t.Fatalf("failed to store session: %v", err)
+	}
+
+	sessions := eris.NewSessionStore[string, int](time.Hour, nil)
+	if err := sessions.Persist(store, "sessions/"); err != nil {
+		t.Fatalf("failed to persist sessions: %v", err)
+	}
+	t.Cleanup(sessions.Clear)
+	if !sessions.Touch("game") {
+		t.Fatal("persisted session wasn't loaded")
+	}
+
+	// A restarted store has to pick up the extended deadline, not the one the entry was loaded with.
+	var record sessionRecord
+	if err := store.GetJSON("sessions/game", &record); err != nil {
+		t.Fatalf("failed to load session: %v", err)
+	}
+	if !record.Expires.After(deadline) {
+		t.Errorf("persisted deadline = %s, want it extended past %s", record.Expires, deadline)
+	}
+}
+
+func TestSessionStoreExpiresWhileDown(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+	store := bot.PluginStore("Sessions")
+
+	if err := store.SetJSON("sessions/game", sessionRecord{
+		Key:     "game",
+		Value:   1,
+		TTL:     time.Hour,
+		Expires: time.Now().Add(-time.Minute),
+	}); err != nil {
+		t.Fatalf("failed to store session: %v", err)
+	}
+
+	// An entry that expired while the bot was down still has its expiry callback called.
+	expired := make(chan int, 1)
+	sessions := eris.NewSessionStore[string, int](time.Hour, func(_ string, value int) {
+		expired <- value
+	})
+	if err := sessions.Persist(store, "sessions/"); err != nil {
+		t.Fatalf("failed to persist sessions: %v", err)
+	}
+
+	select {
+	case value := <-expired:
+		if value != 1 {
+			t.Errorf("expired value = %d, want 1", value)
+		}
+	case <-time.After(server.Timeout):
+		t.Fatal("session never expired")
+	}
+
+	if _, err := store.Get("sessions/game"); err == nil {
+		t.Error("expired session is still persisted")
+	}
+}
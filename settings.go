@@ -0,0 +1,438 @@
+package eris
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// SettingType is the type of value a Setting holds.
+type SettingType int
+
+const (
+	// SettingString values are strings.
+	SettingString SettingType = iota
+	// SettingInt values are ints.
+	SettingInt
+	// SettingNumber values are float64s.
+	SettingNumber
+	// SettingBool values are bools.
+	SettingBool
+	// SettingChannel values are channel ids.
+	SettingChannel
+	// SettingRole values are role ids.
+	SettingRole
+)
+
+// Setting describes a per-guild plugin setting that guild admins can change with the /config command.
+type Setting struct {
+	Name        string
+	Description string
+	Type        SettingType
+	// Default is the value used until the setting is changed, and has to be of the Go type matching Type.
+	Default any
+	// Min and Max bound SettingInt and SettingNumber values, and the length of SettingString values. They are ignored
+	// if both are zero.
+	Min float64
+	Max float64
+	// Choices restricts SettingString values to the listed ones.
+	Choices []string
+}
+
+// SettingsProvider can optionally be implemented by a plugin to declare settings that are changed per guild through
+// the /config command. Their values are read with Bot.Setting or Request.Setting.
+type SettingsProvider interface {
+	Settings() []Setting
+}
+
+// parse converts user input into a value of the setting's type, checking it against the setting's bounds and choices.
+func (s Setting) parse(input string) (any, error) {
+	input = strings.TrimSpace(input)
+
+	switch s.Type {
+	case SettingInt:
+		value, err := strconv.Atoi(input)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a whole number", input)
+		}
+		return value, s.checkBounds(float64(value))
+	case SettingNumber:
+		value, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", input)
+		}
+		return value, s.checkBounds(value)
+	case SettingBool:
+		switch strings.ToLower(input) {
+		case "true", "yes", "on", "1":
+			return true, nil
+		case "false", "no", "off", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%q is not true or false", input)
+	case SettingChannel:
+		id := strings.TrimSuffix(strings.TrimPrefix(input, "<#"), ">")
+		if !isSnowflake(id) {
+			return nil, fmt.Errorf("%q is not a channel", input)
+		}
+		return id, nil
+	case SettingRole:
+		id := strings.TrimSuffix(strings.TrimPrefix(input, "<@&"), ">")
+		if !isSnowflake(id) {
+			return nil, fmt.Errorf("%q is not a role", input)
+		}
+		return id, nil
+	default:
+		if len(s.Choices) > 0 && !slices.Contains(s.Choices, input) {
+			return nil, fmt.Errorf("%q is not one of %s", input, strings.Join(s.Choices, ", "))
+		}
+		return input, s.checkBounds(float64(len(input)))
+	}
+}
+
+// checkBounds returns an error if value is outside of the setting's bounds.
+func (s Setting) checkBounds(value float64) error {
+	if s.Min == 0 && s.Max == 0 {
+		return nil
+	}
+	if value < s.Min || value > s.Max {
+		if s.Type == SettingString {
+			return fmt.Errorf("must be between %g and %g characters long", s.Min, s.Max)
+		}
+		return fmt.Errorf("must be between %g and %g", s.Min, s.Max)
+	}
+
+	return nil
+}
+
+// decode converts a stored JSON value back into the setting's Go type.
+func (s Setting) decode(data []byte) (any, error) {
+	var err error
+	switch s.Type {
+	case SettingInt:
+		var value int
+		err = json.Unmarshal(data, &value)
+		return value, err
+	case SettingNumber:
+		var value float64
+		err = json.Unmarshal(data, &value)
+		return value, err
+	case SettingBool:
+		var value bool
+		err = json.Unmarshal(data, &value)
+		return value, err
+	default:
+		var value string
+		err = json.Unmarshal(data, &value)
+		return value, err
+	}
+}
+
+// format renders a value for display in Discord.
+func (s Setting) format(value any) string {
+	switch s.Type {
+	case SettingChannel:
+		if value == "" || value == nil {
+			return "not set"
+		}
+		return fmt.Sprintf("<#%v>", value)
+	case SettingRole:
+		if value == "" || value == nil {
+			return "not set"
+		}
+		return fmt.Sprintf("<@&%v>", value)
+	default:
+		return fmt.Sprintf("`%v`", value)
+	}
+}
+
+// pluginSetting looks up a setting declared by a loaded plugin.
+func (b *Bot) pluginSetting(pluginName string, name string) (Setting, error) {
+	plugin, ok := b.loadedPlugin(pluginName)
+	if !ok {
+		return Setting{}, fmt.Errorf("plugin %q is not loaded", pluginName)
+	}
+
+	if provider, ok := plugin.(SettingsProvider); ok {
+		for _, setting := range provider.Settings() {
+			if setting.Name == name {
+				return setting, nil
+			}
+		}
+	}
+
+	return Setting{}, fmt.Errorf("plugin %q has no setting %q", pluginName, name)
+}
+
+// Setting returns the value of a plugin's setting in the guild, or its default if it hasn't been changed. Values have
+// the Go type matching the setting's Type: string, int, float64 or bool, with channels and roles given as ids.
+func (b *Bot) Setting(pluginName string, guildId string, name string) (any, error) {
+	setting, err := b.pluginSetting(pluginName, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if guildId == "" {
+		return setting.Default, nil
+	}
+
+	data, err := b.PluginStore(pluginName).Guild(guildId).Get("settings/" + name)
+	if errors.Is(err, ErrNotFound) {
+		return setting.Default, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return setting.decode(data)
+}
+
+// SetSetting validates input and stores it as the value of a plugin's setting in the guild.
+func (b *Bot) SetSetting(pluginName string, guildId string, name string, input string) (any, error) {
+	setting, err := b.pluginSetting(pluginName, name)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := setting.parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = b.PluginStore(pluginName).Guild(guildId).SetJSON("settings/"+name, value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// Setting returns the value of the named setting of the request's plugin in the request's guild. The setting's default
+// is returned if it can't be read.
+func (r *Request) Setting(name string) any {
+	value, err := r.bot.Setting(r.Plugin, r.Interaction.GuildID, name)
+	if err != nil {
+		r.bot.Logger.Warn("failed to read setting, using default",
+			slog.String("plugin", r.Plugin),
+			slog.String("setting", name),
+			slog.String("error", err.Error()),
+		)
+
+		setting, _ := r.bot.pluginSetting(r.Plugin, name)
+		return setting.Default
+	}
+
+	return value
+}
+
+// SettingsManager is the built-in plugin that provides the /config command for changing plugin settings.
+type SettingsManager struct {
+	bot *Bot
+}
+
+func (s SettingsManager) Name() string {
+	return "Settings"
+}
+
+func (s SettingsManager) Description() string {
+	return "Changes plugin settings for a server"
+}
+
+func (s SettingsManager) Handlers() map[string]any {
+	return nil
+}
+
+func (s SettingsManager) Routes() map[string]HandlerFunc {
+	routes := make(map[string]HandlerFunc)
+
+	routes["config"] = s.config
+
+	return routes
+}
+
+func (s SettingsManager) Autocomplete() map[string]AutocompleteFunc {
+	providers := make(map[string]AutocompleteFunc)
+
+	providers["config plugin"] = s.suggestPlugins
+	providers["config setting"] = s.suggestSettings
+	providers["config value"] = s.suggestValues
+
+	return providers
+}
+
+func (s SettingsManager) Permissions() map[string]Permission {
+	return map[string]Permission{"": PermissionGuildAdmin}
+}
+
+func (s SettingsManager) Commands() map[string]*discordgo.ApplicationCommand {
+	commands := make(map[string]*discordgo.ApplicationCommand)
+
+	dmPermission := false
+	commands["config_cmd"] = &discordgo.ApplicationCommand{
+		Name:         "config",
+		Description:  "Change plugin settings for this server",
+		DMPermission: &dmPermission,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:         "plugin",
+				Description:  "Plugin to configure",
+				Type:         discordgo.ApplicationCommandOptionString,
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Name:         "setting",
+				Description:  "Setting to view or change",
+				Type:         discordgo.ApplicationCommandOptionString,
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Name:         "value",
+				Description:  "New value, leave empty to view the current one",
+				Type:         discordgo.ApplicationCommandOptionString,
+				Autocomplete: true,
+			},
+		},
+	}
+
+	return commands
+}
+
+func (s SettingsManager) Intents() []discordgo.Intent {
+	return nil
+}
+
+// config shows or changes a setting.
+func (s SettingsManager) config(r *Request) error {
+	guildId := r.Interaction.GuildID
+	if guildId == "" {
+		return r.Respond().Ephemeral().Message("Settings can only be changed in a server.").Send()
+	}
+
+	pluginKey, _ := r.Option("plugin").(string)
+	name, _ := r.Option("setting").(string)
+	input, hasValue := r.Option("value").(string)
+
+	pluginName, ok := s.pluginName(pluginKey)
+	if !ok {
+		return r.Respond().Ephemeral().Message(fmt.Sprintf("Plugin %q doesn't exist.", pluginKey)).Send()
+	}
+
+	setting, err := s.bot.pluginSetting(pluginName, name)
+	if err != nil {
+		return r.Respond().Ephemeral().
+			Message(fmt.Sprintf("%s doesn't have a setting called %q.", pluginName, name)).Send()
+	}
+
+	if !hasValue {
+		value, err := s.bot.Setting(pluginName, guildId, name)
+		if err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf("**%s %s** is %s.", pluginName, name, setting.format(value))
+		if setting.Description != "" {
+			message += "\n" + setting.Description
+		}
+
+		return r.Respond().Ephemeral().Message(message).Send()
+	}
+
+	value, err := s.bot.SetSetting(pluginName, guildId, name, input)
+	if err != nil {
+		return r.Respond().Ephemeral().Message(fmt.Sprintf("Invalid value for %s: %s.", name, err)).Send()
+	}
+
+	return r.Respond().Ephemeral().
+		Message(fmt.Sprintf("**%s %s** set to %s.", pluginName, name, setting.format(value))).Send()
+}
+
+// suggestPlugins suggests the plugins that have settings.
+func (s SettingsManager) suggestPlugins(r *AutocompleteRequest) error {
+	choices := r.Choices().Filter(r.Input)
+	for _, pluginName := range s.configurablePlugins() {
+		if choices.Full() {
+			break
+		}
+		choices.Choice(pluginName, PluginKey(pluginName))
+	}
+
+	return choices.Send()
+}
+
+// suggestSettings suggests the settings of the chosen plugin.
+func (s SettingsManager) suggestSettings(r *AutocompleteRequest) error {
+	choices := r.Choices().Filter(r.Input)
+
+	pluginKey, _ := r.Option("plugin").(string)
+	if pluginName, ok := s.pluginName(pluginKey); ok {
+		// The plugin may have been unloaded since its name was resolved.
+		plugin, _ := s.bot.loadedPlugin(pluginName)
+		if provider, ok := plugin.(SettingsProvider); ok {
+			for _, setting := range provider.Settings() {
+				if choices.Full() {
+					break
+				}
+				choices.Choice(setting.Name, setting.Name)
+			}
+		}
+	}
+
+	return choices.Send()
+}
+
+// suggestValues suggests values for the chosen setting, if it only takes a few.
+func (s SettingsManager) suggestValues(r *AutocompleteRequest) error {
+	choices := r.Choices().Filter(r.Input)
+
+	pluginKey, _ := r.Option("plugin").(string)
+	name, _ := r.Option("setting").(string)
+	if pluginName, ok := s.pluginName(pluginKey); ok {
+		if setting, err := s.bot.pluginSetting(pluginName, name); err == nil {
+			switch {
+			case setting.Type == SettingBool:
+				choices.Choices("true", "false")
+			case len(setting.Choices) > 0:
+				for _, choice := range setting.Choices {
+					if choices.Full() {
+						break
+					}
+					choices.Choice(choice, choice)
+				}
+			}
+		}
+	}
+
+	return choices.Send()
+}
+
+// configurablePlugins returns the sorted names of the loaded plugins that declare settings.
+func (s SettingsManager) configurablePlugins() []string {
+	var names []string
+	for name, plugin := range s.bot.loadedPlugins() {
+		if provider, ok := plugin.(SettingsProvider); ok && len(provider.Settings()) > 0 {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// pluginName resolves a plugin key, as offered by autocomplete, or a plugin name to the name of a configurable plugin.
+func (s SettingsManager) pluginName(key string) (string, bool) {
+	for _, name := range s.configurablePlugins() {
+		if name == key || PluginKey(name) == PluginKey(key) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
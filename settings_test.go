@@ -0,0 +1,118 @@
+package eris_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+// settingsPlugin is a test plugin that declares a single setting.
+type settingsPlugin struct {
+	*testPlugin
+}
+
+func (p *settingsPlugin) Settings() []eris.Setting {
+	return []eris.Setting{{Name: "greeting", Description: "Greeting", Type: eris.SettingString, Default: "hi"}}
+}
+
+// roundsPlugin is a test plugin with a bounded setting that its route reports.
+type roundsPlugin struct {
+	*testPlugin
+}
+
+func (p *roundsPlugin) Settings() []eris.Setting {
+	return []eris.Setting{{Name: "rounds", Description: "Rounds per game", Type: eris.SettingInt, Default: 5, Min: 1, Max: 20}}
+}
+
+// config returns a /config interaction made in the guild.
+func config(guildId string, plugin string, setting string, value string) *discordgo.Interaction {
+	return eristest.InGuild(eristest.SlashCommand("500", "config",
+		eristest.Option("plugin", discordgo.ApplicationCommandOptionString, plugin),
+		eristest.Option("setting", discordgo.ApplicationCommandOptionString, setting),
+		eristest.Option("value", discordgo.ApplicationCommandOptionString, value)), guildId, "10")
+}
+
+func TestConfigSetting(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{AdminIds: []string{"500"}})
+
+	plugin := &roundsPlugin{&testPlugin{
+		name:     "Game",
+		commands: map[string]*discordgo.ApplicationCommand{"rounds": chatCommand("rounds", "Shows the rounds")},
+		routes: map[string]eris.HandlerFunc{
+			"rounds": func(r *eris.Request) error {
+				return r.Respond().Message(fmt.Sprint(r.Setting("rounds"))).Send()
+			},
+		},
+	}}
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	for _, test := range []struct {
+		interaction *discordgo.Interaction
+		want        string
+	}{
+		{config("1", "game", "rounds", "50"), "Invalid value for rounds: must be between 1 and 20."},
+		{config("1", "game", "rounds", "ten"), "Invalid value for rounds: \"ten\" is not a whole number."},
+		{config("1", "game", "rounds", "10"), "**Game rounds** set to `10`."},
+		// The value is kept per guild, so other guilds still get the default.
+		{eristest.InGuild(eristest.SlashCommand("500", "rounds"), "1", "10"), "10"},
+		{eristest.InGuild(eristest.SlashCommand("500", "rounds"), "2", "20"), "5"},
+	} {
+		interaction := server.InteractionCreate(test.interaction)
+		if response := server.WaitForResponse(interaction.ID); response.Data.Content != test.want {
+			t.Errorf("response = %q, want %q", response.Data.Content, test.want)
+		}
+	}
+}
+
+// suggestSettings returns an autocomplete interaction asking for the settings of the plugin.
+func suggestSettings(plugin string) *discordgo.Interaction {
+	return eristest.Autocomplete("500", "config",
+		eristest.Option("plugin", discordgo.ApplicationCommandOptionString, plugin),
+		eristest.Focused(eristest.Option("setting", discordgo.ApplicationCommandOptionString, "")))
+}
+
+func TestSuggestSettings(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{AdminIds: []string{"500"}})
+
+	if err := bot.AddPlugin(&settingsPlugin{&testPlugin{name: "Greeter"}}); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	interaction := server.InteractionCreate(suggestSettings(eris.PluginKey("Greeter")))
+	response := server.WaitForResponse(interaction.ID)
+	if len(response.Data.Choices) != 1 || response.Data.Choices[0].Name != "greeting" {
+		t.Errorf("suggested settings = %+v, want greeting", response.Data.Choices)
+	}
+
+	// Suggestions may be asked for while the plugin is unloaded, which must neither panic nor race.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			if err := bot.UnloadPlugin("Greeter"); err != nil {
+				t.Errorf("failed to unload plugin: %v", err)
+			}
+			if err := bot.AddPlugin(&settingsPlugin{&testPlugin{name: "Greeter"}}); err != nil {
+				t.Errorf("failed to add plugin: %v", err)
+			}
+		}
+	}()
+
+	// Each suggestion is awaited before the next is asked for, so that they keep coming while the plugin is swapped.
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+			interaction := server.InteractionCreate(suggestSettings(eris.PluginKey("Greeter")))
+			server.WaitForResponse(interaction.ID)
+		}
+	}
+}
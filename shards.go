@@ -0,0 +1,213 @@
+package eris
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// identifyInterval is how long Discord requires between identifies of the same rate limit bucket.
+const identifyInterval = 5 * time.Second
+
+// ShardState is the status of a single gateway shard.
+type ShardState struct {
+	Id      int
+	State   BotState
+	Latency time.Duration
+	Guilds  int
+}
+
+// BotStatus is the status of the bot as a whole, along with that of each of its shards.
+type BotStatus struct {
+	State  BotState
+	Shards []ShardState
+}
+
+// State returns the status of the bot and of each of its shards. A shard of a started bot is reported as UnknownState
+// while it's reconnecting.
+func (b *Bot) State() BotStatus {
+	b.shardsLock.RLock()
+	defer b.shardsLock.RUnlock()
+
+	status := BotStatus{
+		State:  b.state,
+		Shards: make([]ShardState, 0, len(b.shards)),
+	}
+
+	for index, session := range b.shards {
+		shard := ShardState{Id: index, State: b.state}
+
+		session.RLock()
+		if b.state == StartedState && !session.DataReady {
+			shard.State = UnknownState
+		}
+		// The latency is only meaningful once the connection has sent its first heartbeat, and is negative while one is
+		// awaiting its acknowledgement. discordgo marks the session ready under its lock after sending the heartbeat,
+		// so checking that first also keeps the read from racing with it.
+		if session.DataReady {
+			if latency := session.HeartbeatLatency(); latency >= 0 {
+				shard.Latency = latency
+			}
+		}
+		session.RUnlock()
+
+		if session.State != nil {
+			session.State.RLock()
+			shard.Guilds = len(session.State.Guilds)
+			session.State.RUnlock()
+		}
+
+		status.Shards = append(status.Shards, shard)
+	}
+
+	return status
+}
+
+// setState changes the state of the bot. The shards lock is held so that State can be called from other goroutines.
+func (b *Bot) setState(state BotState) {
+	b.shardsLock.Lock()
+	defer b.shardsLock.Unlock()
+
+	b.state = state
+}
+
+// session returns the primary session together with the state of the bot. Both are read under the shards lock, since
+// reidentify replaces the session and setState changes the state while handlers may be reading them.
+func (b *Bot) session() (*discordgo.Session, BotState) {
+	b.shardsLock.RLock()
+	defer b.shardsLock.RUnlock()
+
+	return b.discordSession, b.state
+}
+
+// prepareShards resizes the bot to the shard count set in Config.ShardCount, or the one recommended by Discord, and
+// returns how many shards may identify at once.
+func (b *Bot) prepareShards() (int, error) {
+	count := b.config.ShardCount
+	concurrency := 1
+
+	// A single shard can identify right away, so there's nothing to look up.
+	if count != 1 {
+		gateway, err := b.discordSession.GatewayBot()
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch the recommended shard count: %w", err)
+		}
+		if count == 0 {
+			count = gateway.Shards
+		}
+		concurrency = max(gateway.SessionStartLimit.MaxConcurrency, 1)
+	}
+
+	b.reshard(max(count, 1))
+
+	return concurrency, nil
+}
+
+// reshard replaces the shards of a stopped bot with count sessions cloned from the first one, and moves the handlers
+// over to them.
+func (b *Bot) reshard(count int) {
+	if len(b.shards) == count && b.shards[0] == b.discordSession {
+		return
+	}
+
+	shards := make([]*discordgo.Session, count)
+	for index := range shards {
+		session := b.discordSession
+		if index > 0 {
+			session = cloneSession(b.discordSession)
+		}
+		session.ShardID = index
+		session.ShardCount = count
+		session.Identify.Shard = nil
+		shards[index] = session
+	}
+
+	b.shardsLock.Lock()
+	b.shards = shards
+	b.shardsLock.Unlock()
+
+	b.registerHandlers()
+}
+
+// registerHandlers removes every handler from the sessions it was added to and adds it to each of the current shards.
+func (b *Bot) registerHandlers() {
+	b.handlersLock.Lock()
+	defer b.handlersLock.Unlock()
+
+	for name, handler := range b.handlerFuncs {
+		if remove, ok := b.handlers[name]; ok {
+			remove()
+		}
+		b.handlers[name] = b.addShardHandler(handler)
+	}
+}
+
+// addShardHandler adds the handler to every shard and returns a function that removes it from all of them.
+func (b *Bot) addShardHandler(handler any) func() {
+	b.shardsLock.RLock()
+	shards := b.shards
+	b.shardsLock.RUnlock()
+
+	removers := make([]func(), 0, len(shards))
+	for _, session := range shards {
+		removers = append(removers, session.AddHandler(handler))
+	}
+
+	return func() {
+		for _, remove := range removers {
+			remove()
+		}
+	}
+}
+
+// openShards connects every shard, identifying up to concurrency shards per identifyInterval. Shards that were opened
+// are closed again if any of them fails.
+func (b *Bot) openShards(concurrency int) error {
+	for index, session := range b.shards {
+		if index > 0 && index%concurrency == 0 {
+			time.Sleep(identifyInterval)
+		}
+
+		if err := session.Open(); err != nil {
+			for _, opened := range b.shards[:index] {
+				_ = opened.Close()
+			}
+			return err
+		}
+
+		if len(b.shards) > 1 {
+			b.Logger.Info("shard connected", slog.Int("shard", index), slog.Int("shards", len(b.shards)))
+		}
+	}
+
+	return nil
+}
+
+// closeShards disconnects every shard.
+func (b *Bot) closeShards() error {
+	var errs []error
+	for index, session := range b.shards {
+		if err := session.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing shard %d: %w", index, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// shardFor returns the shard that receives the events of the guild. Direct messages are received by shard 0.
+func (b *Bot) shardFor(guildId string) *discordgo.Session {
+	b.shardsLock.RLock()
+	defer b.shardsLock.RUnlock()
+
+	id, err := strconv.ParseUint(guildId, 10, 64)
+	if err != nil || len(b.shards) == 0 {
+		return b.discordSession
+	}
+
+	return b.shards[(id>>22)%uint64(len(b.shards))]
+}
@@ -0,0 +1,102 @@
+package eris_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+// logBuffer collects log output that is written from several goroutines.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestStartShardedWhileAddingHandler(t *testing.T) {
+	server := eristest.NewServer(t)
+	server.SetRecommendedShards(2, 2)
+
+	var logs logBuffer
+	bot, err := server.NewBot(eris.Config{DeferConnect: true}, slog.NewJSONHandler(&logs, nil))
+	if err != nil {
+		t.Fatalf("failed to create bot: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = bot.Stop()
+	})
+	logger := bot.Logger
+
+	type delivery struct {
+		content string
+		shardId int
+	}
+	received := make(chan delivery, 2)
+
+	// A handler added while the bot is resharding ends up on every shard.
+	added := make(chan struct{})
+	go func() {
+		defer close(added)
+		bot.AddHandler("messages", func(session *discordgo.Session, message *discordgo.MessageCreate) {
+			received <- delivery{content: message.Content, shardId: session.ShardID}
+		})
+	}()
+	if err = bot.Start(); err != nil {
+		t.Fatalf("failed to start bot: %v", err)
+	}
+	<-added
+
+	if shards := server.Shards(); len(shards) != 2 {
+		t.Fatalf("shards = %v, want 2", shards)
+	}
+
+	server.MessageCreate(&discordgo.Message{GuildID: "4194304", ChannelID: "400", Content: "odd"})
+	server.MessageCreate(&discordgo.Message{GuildID: "8388608", ChannelID: "400", Content: "even"})
+
+	want := map[string]int{"odd": 1, "even": 0}
+	for range want {
+		select {
+		case delivery := <-received:
+			if shardId, ok := want[delivery.content]; !ok || delivery.shardId != shardId {
+				t.Errorf("%s received by shard %d, want %d", delivery.content, delivery.shardId, shardId)
+			}
+		case <-time.After(server.Timeout):
+			t.Fatal("message was never dispatched")
+		}
+	}
+
+	// The logger isn't replaced once the bot connects, but its records identify the bot.
+	if bot.Logger != logger {
+		t.Error("logger was replaced by Start")
+	}
+	bot.Logger.Info("sharded")
+	var record string
+	for _, line := range strings.Split(logs.String(), "\n") {
+		if strings.Contains(line, `"msg":"sharded"`) {
+			record = line
+		}
+	}
+	if !strings.Contains(record, `"Id":"`+server.AppId+`"`) {
+		t.Errorf("log record = %q, want the bot's id", record)
+	}
+}
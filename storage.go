@@ -0,0 +1,164 @@
+package eris
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned by Storage and Store when a key doesn't exist.
+var ErrNotFound = errors.New("key not found")
+
+// Storage is a key/value backend that the bot persists plugin data in. Keys are grouped into namespaces, which
+// backends keep separate from one another. Implementations must be safe for concurrent use.
+type Storage interface {
+	// Get returns the value of the key, or ErrNotFound.
+	Get(namespace string, key string) ([]byte, error)
+	// Set stores the value under the key, replacing any existing value.
+	Set(namespace string, key string, value []byte) error
+	// Delete removes the key. Deleting a key that doesn't exist is not an error.
+	Delete(namespace string, key string) error
+	// Keys returns the sorted keys of the namespace that start with prefix.
+	Keys(namespace string, prefix string) ([]string, error)
+	// Close releases the backend's resources.
+	Close() error
+}
+
+// Store is a view of Storage limited to a single namespace, as handed to plugins by Bot.PluginStore and Request.Store.
+type Store struct {
+	storage   Storage
+	namespace string
+}
+
+// NewStore returns a Store for the namespace of storage.
+func NewStore(storage Storage, namespace string) *Store {
+	return &Store{storage: storage, namespace: namespace}
+}
+
+// Namespace returns the namespace the store reads and writes.
+func (s *Store) Namespace() string {
+	return s.namespace
+}
+
+// Guild returns a store for data that belongs to a single guild, nested in the namespace of s.
+func (s *Store) Guild(guildId string) *Store {
+	return NewStore(s.storage, s.namespace+"/guilds/"+guildId)
+}
+
+// Get returns the value of the key, or ErrNotFound.
+func (s *Store) Get(key string) ([]byte, error) {
+	return s.storage.Get(s.namespace, key)
+}
+
+// Set stores the value under the key.
+func (s *Store) Set(key string, value []byte) error {
+	return s.storage.Set(s.namespace, key, value)
+}
+
+// Delete removes the key.
+func (s *Store) Delete(key string) error {
+	return s.storage.Delete(s.namespace, key)
+}
+
+// Keys returns the sorted keys that start with prefix.
+func (s *Store) Keys(prefix string) ([]string, error) {
+	return s.storage.Keys(s.namespace, prefix)
+}
+
+// GetJSON decodes the JSON document stored under the key into v, or returns ErrNotFound.
+func (s *Store) GetJSON(key string, v any) error {
+	data, err := s.Get(key)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// SetJSON stores v as a JSON document under the key.
+func (s *Store) SetJSON(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.Set(key, data)
+}
+
+// PluginStore returns the store namespaced to the plugin with the given name. Plugins can hold on to it, for example
+// from Init.
+func (b *Bot) PluginStore(name string) *Store {
+	return NewStore(b.storage, "plugins/"+PluginKey(name))
+}
+
+// Storage returns the backend the bot persists data in.
+func (b *Bot) Storage() Storage {
+	return b.storage
+}
+
+// MemoryStorage is a Storage that keeps everything in memory. It is the default backend when Config.StoragePath is
+// unset, and nothing survives a restart.
+type MemoryStorage struct {
+	lock       sync.RWMutex
+	namespaces map[string]map[string][]byte
+}
+
+// NewMemoryStorage returns an empty MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{namespaces: make(map[string]map[string][]byte)}
+}
+
+func (m *MemoryStorage) Get(namespace string, key string) ([]byte, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	value, ok := m.namespaces[namespace][key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return append([]byte(nil), value...), nil
+}
+
+func (m *MemoryStorage) Set(namespace string, key string, value []byte) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.namespaces[namespace]; !ok {
+		m.namespaces[namespace] = make(map[string][]byte)
+	}
+	m.namespaces[namespace][key] = append([]byte(nil), value...)
+
+	return nil
+}
+
+func (m *MemoryStorage) Delete(namespace string, key string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.namespaces[namespace], key)
+
+	return nil
+}
+
+func (m *MemoryStorage) Keys(namespace string, prefix string) ([]string, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var keys []string
+	for key := range m.namespaces[namespace] {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func (m *MemoryStorage) Close() error {
+	return nil
+}
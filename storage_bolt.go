@@ -0,0 +1,90 @@
+package eris
+
+import (
+	"bytes"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltStorage is a Storage backed by a single bbolt database file. Each namespace is kept in its own bucket.
+type BoltStorage struct {
+	db *bolt.DB
+}
+
+// NewBoltStorage opens the database file at path, creating it if it doesn't exist. The file is locked for as long as
+// the storage is open.
+func NewBoltStorage(path string) (*BoltStorage, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	return &BoltStorage{db: db}, nil
+}
+
+func (s *BoltStorage) Get(namespace string, key string) ([]byte, error) {
+	var value []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(namespace))
+		if bucket == nil {
+			return ErrNotFound
+		}
+
+		stored := bucket.Get([]byte(key))
+		if stored == nil {
+			return ErrNotFound
+		}
+
+		// Values are only valid for the life of the transaction.
+		value = append([]byte(nil), stored...)
+		return nil
+	})
+
+	return value, err
+}
+
+func (s *BoltStorage) Set(namespace string, key string, value []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(namespace))
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(key), value)
+	})
+}
+
+func (s *BoltStorage) Delete(namespace string, key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(namespace))
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.Delete([]byte(key))
+	})
+}
+
+func (s *BoltStorage) Keys(namespace string, prefix string) ([]string, error) {
+	var keys []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(namespace))
+		if bucket == nil {
+			return nil
+		}
+
+		cursor := bucket.Cursor()
+		for key, _ := cursor.Seek([]byte(prefix)); key != nil && bytes.HasPrefix(key, []byte(prefix)); key, _ = cursor.Next() {
+			keys = append(keys, string(key))
+		}
+
+		return nil
+	})
+
+	return keys, err
+}
+
+func (s *BoltStorage) Close() error {
+	return s.db.Close()
+}
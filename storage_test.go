@@ -0,0 +1,117 @@
+package eris_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/olympus-go/eris"
+	"github.com/olympus-go/eris/eristest"
+)
+
+func TestStorageBackends(t *testing.T) {
+	for name, open := range map[string]func(t *testing.T) eris.Storage{
+		"memory": func(_ *testing.T) eris.Storage {
+			return eris.NewMemoryStorage()
+		},
+		"bolt": func(t *testing.T) eris.Storage {
+			storage, err := eris.NewBoltStorage(filepath.Join(t.TempDir(), "eris.db"))
+			if err != nil {
+				t.Fatalf("failed to open storage: %v", err)
+			}
+			return storage
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			storage := open(t)
+			t.Cleanup(func() {
+				_ = storage.Close()
+			})
+
+			store := eris.NewStore(storage, "plugins/game")
+			for _, key := range []string{"games/2", "games/1", "stats"} {
+				if err := store.SetJSON(key, map[string]string{"key": key}); err != nil {
+					t.Fatalf("failed to set %q: %v", key, err)
+				}
+			}
+
+			var game map[string]string
+			if err := store.GetJSON("games/1", &game); err != nil || game["key"] != "games/1" {
+				t.Errorf("GetJSON returned %v, %v, want games/1", game, err)
+			}
+			if keys, err := store.Keys("games/"); err != nil || !reflect.DeepEqual(keys, []string{"games/1", "games/2"}) {
+				t.Errorf("Keys returned %v, %v, want games/1 and games/2", keys, err)
+			}
+
+			// Namespaces, including the nested ones of guilds, are kept apart.
+			for _, other := range []*eris.Store{eris.NewStore(storage, "plugins/other"), store.Guild("42")} {
+				if _, err := other.Get("stats"); !errors.Is(err, eris.ErrNotFound) {
+					t.Errorf("namespace %q sees the key of another: %v", other.Namespace(), err)
+				}
+			}
+
+			if err := store.Delete("stats"); err != nil {
+				t.Fatalf("failed to delete: %v", err)
+			}
+			if _, err := store.Get("stats"); !errors.Is(err, eris.ErrNotFound) {
+				t.Errorf("Get after Delete returned %v, want ErrNotFound", err)
+			}
+			if err := store.Delete("stats"); err != nil {
+				t.Errorf("deleting a missing key returned %v", err)
+			}
+		})
+	}
+}
+
+func TestBoltStoragePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eris.db")
+
+	storage, err := eris.NewBoltStorage(path)
+	if err != nil {
+		t.Fatalf("failed to open storage: %v", err)
+	}
+	if err := storage.Set("plugins/game", "stats", []byte("3")); err != nil {
+		t.Fatalf("failed to set: %v", err)
+	}
+	if err := storage.Close(); err != nil {
+		t.Fatalf("failed to close storage: %v", err)
+	}
+
+	storage, err = eris.NewBoltStorage(path)
+	if err != nil {
+		t.Fatalf("failed to reopen storage: %v", err)
+	}
+	defer storage.Close()
+
+	if value, err := storage.Get("plugins/game", "stats"); err != nil || string(value) != "3" {
+		t.Errorf("Get after reopening returned %q, %v, want 3", value, err)
+	}
+}
+
+func TestRequestStore(t *testing.T) {
+	server := eristest.NewServer(t)
+	bot := newTestBot(t, server, eris.Config{})
+
+	if err := bot.PluginStore("Other").Set("greeting", []byte("hello")); err != nil {
+		t.Fatalf("failed to set: %v", err)
+	}
+
+	// Routes get the store of their own plugin.
+	plugin := &testPlugin{name: "Shop", components: map[string]eris.HandlerFunc{
+		"shop_{id}": func(r *eris.Request) error {
+			if _, err := r.Store.Get("greeting"); !errors.Is(err, eris.ErrNotFound) {
+				return r.Respond().Message("shared").Send()
+			}
+			return r.Respond().Message(r.Store.Namespace()).Send()
+		},
+	}}
+	if err := bot.AddPlugin(plugin); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+
+	interaction := server.InteractionCreate(eristest.Component("500", "shop_1"))
+	if response := server.WaitForResponse(interaction.ID); response.Data.Content != "plugins/shop" {
+		t.Errorf("response = %q, want the namespace plugins/shop", response.Data.Content)
+	}
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// MaxAutocompleteChoices is the most choices Discord accepts in an autocomplete response.
+const MaxAutocompleteChoices = 25
+
+// errChoicesDropped is reported by Send when choices were left out of the response.
+var errChoicesDropped = errors.New("autocomplete choices dropped")
+
+// AutocompleteResponseBuilder builds the choices sent back for an autocomplete interaction. Choices added once it holds
+// MaxAutocompleteChoices are left out, so that the response is never rejected for having too many, but Send reports
+// them. Callers with more choices than that stop adding once Full reports true.
+type AutocompleteResponseBuilder struct {
+	session     *discordgo.Session
+	interaction *discordgo.Interaction
+	filter      string
+	choices     []*discordgo.ApplicationCommandOptionChoice
+	// dropped is the number of matching choices added once the response was full.
+	dropped int
+}
+
+func AutocompleteResponse(session *discordgo.Session, interaction *discordgo.Interaction) *AutocompleteResponseBuilder {
+	return &AutocompleteResponseBuilder{
+		session:     session,
+		interaction: interaction,
+		choices:     []*discordgo.ApplicationCommandOptionChoice{},
+	}
+}
+
+// Filter only keeps the choices added afterwards whose name contains the text, ignoring case. It is usually passed what
+// the user typed so far.
+func (a *AutocompleteResponseBuilder) Filter(text string) *AutocompleteResponseBuilder {
+	a.filter = strings.ToLower(text)
+	return a
+}
+
+// Choice adds a choice, unless it doesn't match the filter. Choices added once the response is full are counted as
+// dropped instead.
+func (a *AutocompleteResponseBuilder) Choice(name string, value any) *AutocompleteResponseBuilder {
+	if !strings.Contains(strings.ToLower(name), a.filter) {
+		return a
+	}
+	if a.Full() {
+		a.dropped++
+		return a
+	}
+
+	a.choices = append(a.choices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: value})
+	return a
+}
+
+// Choices adds several choices that are named after their values.
+func (a *AutocompleteResponseBuilder) Choices(values ...string) *AutocompleteResponseBuilder {
+	for _, value := range values {
+		a.Choice(value, value)
+	}
+
+	return a
+}
+
+// Full reports whether the response holds as many choices as Discord accepts, so that callers can stop looking for
+// more.
+func (a *AutocompleteResponseBuilder) Full() bool {
+	return len(a.choices) >= MaxAutocompleteChoices
+}
+
+// Send responds with the choices. If choices were dropped, the ones that fit are still sent, but an error reporting how
+// many were dropped is returned.
+func (a *AutocompleteResponseBuilder) Send() error {
+	err := a.session.InteractionRespond(a.interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: a.choices},
+	})
+	if err == nil && a.dropped > 0 {
+		err = fmt.Errorf("%w: %d beyond the %d Discord accepts", errChoicesDropped, a.dropped, MaxAutocompleteChoices)
+	}
+
+	return err
+}
+
+func (a *AutocompleteResponseBuilder) SendWithLog(logger *slog.Logger) {
+	err := a.Send()
+	switch {
+	case errors.Is(err, errChoicesDropped):
+		logger.Warn("dropped autocomplete choices",
+			slog.Int("dropped", a.dropped),
+			slog.Any("interaction", a.interaction),
+		)
+	case err != nil:
+		logger.Error("failed to send autocomplete choices",
+			slog.String("error", err.Error()),
+			slog.Any("interaction", a.interaction),
+		)
+	}
+}
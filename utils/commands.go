@@ -14,6 +14,29 @@ func CompareApplicationCommand(first, second discordgo.ApplicationCommand) bool
 	if first.Description != second.Description {
 		return false
 	}
+	if commandType(first.Type) != commandType(second.Type) {
+		return false
+	}
+	// Discord treats a missing DMPermission as allowed and a missing NSFW as not age restricted.
+	if boolOr(first.DMPermission, true) != boolOr(second.DMPermission, true) {
+		return false
+	}
+	if boolOr(first.NSFW, false) != boolOr(second.NSFW, false) {
+		return false
+	}
+	if (first.DefaultMemberPermissions == nil) != (second.DefaultMemberPermissions == nil) {
+		return false
+	}
+	if first.DefaultMemberPermissions != nil && *first.DefaultMemberPermissions != *second.DefaultMemberPermissions {
+		return false
+	}
+	if !compareLocalizations(derefLocalizations(first.NameLocalizations), derefLocalizations(second.NameLocalizations)) {
+		return false
+	}
+	if !compareLocalizations(derefLocalizations(first.DescriptionLocalizations),
+		derefLocalizations(second.DescriptionLocalizations)) {
+		return false
+	}
 	if len(first.Options) != len(second.Options) {
 		return false
 	}
@@ -39,6 +62,12 @@ func CompareApplicationCommandOption(first, second discordgo.ApplicationCommandO
 	if first.Required != second.Required {
 		return false
 	}
+	if !compareLocalizations(first.NameLocalizations, second.NameLocalizations) {
+		return false
+	}
+	if !compareLocalizations(first.DescriptionLocalizations, second.DescriptionLocalizations) {
+		return false
+	}
 	if first.Type != second.Type {
 		return false
 	}
@@ -63,6 +92,9 @@ func CompareApplicationCommandOption(first, second discordgo.ApplicationCommandO
 		if (*first.Choices[index]).Name != (*second.Choices[index]).Name {
 			return false
 		}
+		if !compareLocalizations(first.Choices[index].NameLocalizations, second.Choices[index].NameLocalizations) {
+			return false
+		}
 	}
 
 	if first.MinValue != nil && second.MinValue != nil {
@@ -78,6 +110,47 @@ func CompareApplicationCommandOption(first, second discordgo.ApplicationCommandO
 	return true
 }
 
+// compareLocalizations tests two sets of localizations for equality. A missing set equals an empty one, since Discord
+// returns commands without localizations either way.
+func compareLocalizations(first, second map[discordgo.Locale]string) bool {
+	if len(first) != len(second) {
+		return false
+	}
+
+	for locale, value := range first {
+		if other, ok := second[locale]; !ok || other != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// commandType returns the type of a command, which is a chat input command if unset.
+func commandType(commandType discordgo.ApplicationCommandType) discordgo.ApplicationCommandType {
+	if commandType == 0 {
+		return discordgo.ChatApplicationCommand
+	}
+
+	return commandType
+}
+
+func boolOr(value *bool, fallback bool) bool {
+	if value == nil {
+		return fallback
+	}
+
+	return *value
+}
+
+func derefLocalizations(localizations *map[discordgo.Locale]string) map[discordgo.Locale]string {
+	if localizations == nil {
+		return nil
+	}
+
+	return *localizations
+}
+
 // GetCommandOption takes either a discordgo.ApplicationCommandInteractionData or a
 // discordgo.ApplicationCommandInteractionDataOption, and checks that:
 //  1. the `.Name` property matches `name`
@@ -117,6 +190,8 @@ func GetCommandOption(command any, name string, optionName string) *discordgo.Ap
 // IsInteractionMessageComponent checks an interaction to see if it's of type discordgo.InteractionMessageComponent. It
 // also compares the CustomID of it to name using the compareType supplied. compareType can be any of the following:
 // "is", or "startsWith".
+//
+// Deprecated: Plugins implementing eris.ComponentRouter have their components routed by CustomID pattern instead.
 func IsInteractionMessageComponent(i *discordgo.InteractionCreate, compareType string, name string) bool {
 	switch strings.ToLower(compareType) {
 	case "startswith":
@@ -11,6 +11,8 @@ type InteractionResponseBuilder struct {
 	interaction *discordgo.Interaction
 	message     *discordgo.Message
 	response    *discordgo.InteractionResponse
+	// err is returned by Send instead of sending a response that Discord would reject.
+	err error
 }
 
 func InteractionResponse(session *discordgo.Session, interaction *discordgo.Interaction) *InteractionResponseBuilder {
@@ -74,12 +76,26 @@ func (i *InteractionResponseBuilder) Embeds(embeds ...*discordgo.MessageEmbed) *
 	return i
 }
 
+// Modal responds with a modal dialog instead of a message. A modal can't be the response to a modal submit. Send fails
+// without responding if the modal has more inputs than it can show.
+func (i *InteractionResponseBuilder) Modal(modal *ModalBuilder) *InteractionResponseBuilder {
+	i.response.Type = discordgo.InteractionResponseModal
+	i.response.Data = modal.Data()
+	i.err = modal.Err()
+	return i
+}
+
 func (i *InteractionResponseBuilder) Response(response *discordgo.InteractionResponse) *InteractionResponseBuilder {
 	i.response = response
+	i.err = nil
 	return i
 }
 
 func (i *InteractionResponseBuilder) Send() error {
+	if i.err != nil {
+		return i.err
+	}
+
 	return i.session.InteractionRespond(i.interaction, i.response)
 }
 
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// MaxModalInputs is the most text inputs Discord shows in a modal.
+const MaxModalInputs = 5
+
+// ModalBuilder builds a modal dialog of text inputs. Send it with InteractionResponseBuilder.Modal.
+type ModalBuilder struct {
+	customId string
+	title    string
+	inputs   []*TextInputBuilder
+	// dropped is the number of inputs added once the modal was full.
+	dropped int
+}
+
+func Modal(customId string, title string) *ModalBuilder {
+	return &ModalBuilder{
+		customId: customId,
+		title:    title,
+	}
+}
+
+// Inputs adds text inputs to the modal, each on its own row. A modal with inputs beyond MaxModalInputs fails to be
+// sent, since Discord would reject it.
+func (m *ModalBuilder) Inputs(inputs ...*TextInputBuilder) *ModalBuilder {
+	for _, input := range inputs {
+		if len(m.inputs) < MaxModalInputs {
+			m.inputs = append(m.inputs, input)
+		} else {
+			m.dropped++
+		}
+	}
+
+	return m
+}
+
+// Err returns an error if more inputs were added than the modal can show.
+func (m *ModalBuilder) Err() error {
+	if m.dropped > 0 {
+		return fmt.Errorf("modal %q has %d inputs, but Discord only shows %d", m.customId, len(m.inputs)+m.dropped,
+			MaxModalInputs)
+	}
+
+	return nil
+}
+
+// Data returns the modal as the data of an interaction response.
+func (m *ModalBuilder) Data() *discordgo.InteractionResponseData {
+	components := make([]discordgo.MessageComponent, 0, len(m.inputs))
+	for _, input := range m.inputs {
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{input.input},
+		})
+	}
+
+	return &discordgo.InteractionResponseData{
+		CustomID:   m.customId,
+		Title:      m.title,
+		Components: components,
+	}
+}
+
+// TextInputBuilder builds a text input of a modal. Inputs are single line and required unless configured otherwise.
+type TextInputBuilder struct {
+	input discordgo.TextInput
+}
+
+func TextInput(customId string, label string) *TextInputBuilder {
+	return &TextInputBuilder{
+		input: discordgo.TextInput{
+			CustomID: customId,
+			Label:    label,
+			Style:    discordgo.TextInputShort,
+			Required: true,
+		},
+	}
+}
+
+// Short makes the input a single line, which is the default.
+func (t *TextInputBuilder) Short() *TextInputBuilder {
+	t.input.Style = discordgo.TextInputShort
+	return t
+}
+
+// Paragraph makes the input span several lines.
+func (t *TextInputBuilder) Paragraph() *TextInputBuilder {
+	t.input.Style = discordgo.TextInputParagraph
+	return t
+}
+
+func (t *TextInputBuilder) MinLength(length int) *TextInputBuilder {
+	t.input.MinLength = length
+	return t
+}
+
+func (t *TextInputBuilder) MaxLength(length int) *TextInputBuilder {
+	t.input.MaxLength = length
+	return t
+}
+
+func (t *TextInputBuilder) Required(required bool) *TextInputBuilder {
+	t.input.Required = required
+	return t
+}
+
+// Placeholder sets the text shown while the input is empty.
+func (t *TextInputBuilder) Placeholder(placeholder string) *TextInputBuilder {
+	t.input.Placeholder = placeholder
+	return t
+}
+
+// Value pre-fills the input.
+func (t *TextInputBuilder) Value(value string) *TextInputBuilder {
+	t.input.Value = value
+	return t
+}
+
+// ModalValues returns the submitted values of a modal's text inputs, keyed by their CustomID.
+func ModalValues(data discordgo.ModalSubmitInteractionData) map[string]string {
+	values := make(map[string]string)
+
+	var collect func(components []discordgo.MessageComponent)
+	collect = func(components []discordgo.MessageComponent) {
+		for _, component := range components {
+			switch component := component.(type) {
+			case *discordgo.ActionsRow:
+				collect(component.Components)
+			case discordgo.ActionsRow:
+				collect(component.Components)
+			case *discordgo.TextInput:
+				values[component.CustomID] = component.Value
+			case discordgo.TextInput:
+				values[component.CustomID] = component.Value
+			}
+		}
+	}
+	collect(data.Components)
+
+	return values
+}